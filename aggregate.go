@@ -0,0 +1,75 @@
+package geohash
+
+// Seq is an iterator over values, yielding until yield returns false.
+// It has the same shape as the standard library iter.Seq.
+type Seq[T any] func(yield func(T) bool)
+
+// Cluster is the aggregation of all the points falling in a geohash cell
+type Cluster[T any] struct {
+	Count    int
+	Centroid Location
+	Bounds   Region // bounding box of the points, not of the cell
+	Value    T      // result of the reducer, if any
+}
+
+// Reducer folds the value of a point into the value accumulated by its cluster
+type Reducer[T any] func(acc, value T) T
+
+// Aggregate groups the points into clusters by their geohash with the given precision,
+// computing the count, centroid and bounds of each. When reduce is not nil the values
+// of each cluster are folded with it, starting from the value of its first point.
+func Aggregate[T any](points Seq[Item[T]], precision int, reduce Reducer[T]) map[string]Cluster[T] {
+	clusters := make(map[string]Cluster[T])
+	points(func(item Item[T]) bool {
+		loc := item.Location
+		geohash := Encode(loc.lat, loc.lon, precision)
+		cluster, ok := clusters[geohash]
+		if !ok {
+			cluster = Cluster[T]{Centroid: loc, Bounds: NewRegion(loc, loc)}
+			if reduce != nil {
+				cluster.Value = item.Value
+			}
+		} else {
+			if reduce != nil {
+				cluster.Value = reduce(cluster.Value, item.Value)
+			}
+			// Cells never cross the antimeridian, so averaging degrees is safe
+			n := float64(cluster.Count)
+			cluster.Centroid = NewLocation(
+				(cluster.Centroid.lat*n+loc.lat)/(n+1),
+				(cluster.Centroid.lon*n+loc.lon)/(n+1),
+			)
+			cluster.Bounds = NewRegion(
+				NewLocation(min(cluster.Bounds.min.lat, loc.lat), min(cluster.Bounds.min.lon, loc.lon)),
+				NewLocation(max(cluster.Bounds.max.lat, loc.lat), max(cluster.Bounds.max.lon, loc.lon)),
+			)
+		}
+		cluster.Count++
+		clusters[geohash] = cluster
+		return true
+	})
+	return clusters
+}
+
+// Slice returns an iterator over the elements of a slice
+func Slice[T any](s []T) Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range s {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// ZoomPrecision returns the geohash precision for clustering points on a web map
+// (256px Web Mercator tiles) at the given zoom level, so that each tile is split
+// in at least 4 cells across, about one every 64px.
+func ZoomPrecision(zoom int) int {
+	for precision := 1; precision < indexPrecision; precision++ {
+		if lonBits, _ := cellBits(precision); int(lonBits) >= zoom+2 {
+			return precision
+		}
+	}
+	return indexPrecision
+}
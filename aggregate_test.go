@@ -0,0 +1,58 @@
+package geohash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregate(t *testing.T) {
+	points := []Item[int]{
+		{NewLocation(19.43, -99.13), 1},
+		{NewLocation(19.44, -99.14), 2},
+		{NewLocation(19.42, -99.12), 3},
+		{NewLocation(41.90, 12.45), 4},
+	}
+	clusters := Aggregate(Slice(points), 4, func(acc, value int) int { return acc + value })
+	if assert.Len(t, clusters, 2) {
+		mexico := clusters["9g3w"]
+		assert.Equal(t, 3, mexico.Count)
+		assert.Equal(t, 6, mexico.Value)
+		assert.InDelta(t, 19.43, mexico.Centroid.Latitude(), 1e-9)
+		assert.InDelta(t, -99.13, mexico.Centroid.Longitude(), 1e-9)
+		assert.Equal(t, NewRegion(NewLocation(19.42, -99.14), NewLocation(19.44, -99.12)), mexico.Bounds)
+		vatican := clusters["sr2y"]
+		assert.Equal(t, 1, vatican.Count)
+		assert.Equal(t, 4, vatican.Value)
+		assert.Equal(t, points[3].Location, vatican.Centroid)
+		assert.Equal(t, NewRegion(points[3].Location, points[3].Location), vatican.Bounds)
+	}
+	// Without reducer only counts
+	clusters = Aggregate(Slice(points), 1, nil)
+	if assert.Len(t, clusters, 2) {
+		assert.Equal(t, 3, clusters["9"].Count)
+		assert.Equal(t, 0, clusters["9"].Value)
+	}
+	assert.Empty(t, Aggregate(Slice[Item[int]](nil), 5, nil))
+}
+
+func TestSlice(t *testing.T) {
+	var values []int
+	Slice([]int{1, 2, 3, 4})(func(v int) bool {
+		values = append(values, v)
+		return v < 2
+	})
+	assert.Equal(t, []int{1, 2}, values, "Should stop when yield returns false")
+}
+
+func TestZoomPrecision(t *testing.T) {
+	assert.Equal(t, 1, ZoomPrecision(0))
+	assert.Equal(t, 1, ZoomPrecision(1))
+	assert.Equal(t, 2, ZoomPrecision(2))
+	assert.Equal(t, 4, ZoomPrecision(8))
+	assert.Equal(t, 12, ZoomPrecision(30))
+	// Monotonic
+	for zoom := 1; zoom <= 22; zoom++ {
+		assert.GreaterOrEqual(t, ZoomPrecision(zoom), ZoomPrecision(zoom-1))
+	}
+}
@@ -0,0 +1,104 @@
+package geohash
+
+import (
+	"bytes"
+	"math"
+)
+
+// cellBits returns how many of the bits in a geohash of the given precision
+// encode longitude and latitude. Longitude takes the extra bit on odd totals.
+func cellBits(precision int) (lonBits, latBits uint) {
+	total := uint(precision) * 5
+	return (total + 1) / 2, total / 2
+}
+
+// cellSize returns the height (latitude) and width (longitude) in degrees
+// of every cell with the given precision.
+func cellSize(precision int) (height, width float64) {
+	lonBits, latBits := cellBits(precision)
+	return 180 / math.Exp2(float64(latBits)), 360 / math.Exp2(float64(lonBits))
+}
+
+// base32Index returns the 5 bit value [0-31] of a geohash character, -1 if invalid
+func base32Index(char byte) int {
+	return bytes.IndexByte(base32, char)
+}
+
+// cellIndex de-interleaves a geohash into its column (x, west to east)
+// and row (y, south to north) in the grid of cells of the same precision.
+func cellIndex(geohash string) (x, y uint64) {
+	even := true
+	for _, char := range []byte(geohash) {
+		decimal := base32Index(char)
+		for _, mask := range bits {
+			bit := uint64(0)
+			if decimal&mask != 0 {
+				bit = 1
+			}
+			if even {
+				x = x<<1 | bit
+			} else {
+				y = y<<1 | bit
+			}
+			even = !even
+		}
+	}
+	return x, y
+}
+
+// cellHash interleaves a column (x) and row (y) back into a geohash of the given precision
+func cellHash(x, y uint64, precision int) string {
+	lonBits, latBits := cellBits(precision)
+	geohash := make([]byte, precision)
+	even := true
+	for i := range geohash {
+		char := 0
+		for _, mask := range bits {
+			if even {
+				lonBits--
+				if x>>lonBits&1 == 1 {
+					char |= mask
+				}
+			} else {
+				latBits--
+				if y>>latBits&1 == 1 {
+					char |= mask
+				}
+			}
+			even = !even
+		}
+		geohash[i] = base32[char]
+	}
+	return string(geohash)
+}
+
+// Steps calculates the offset in columns (dx, West to East) and rows (dy, South to North)
+// from geohash a to b on the grid of cells of their precision. The columns are counted the
+// shortest way around the antimeridian. Geohashes of different length are compared at the
+// precision of the shorter one.
+func Steps(a, b string) (dx, dy int) {
+	precision := min(len(a), len(b))
+	lonBits, _ := cellBits(precision)
+	columns := int64(1) << lonBits
+	ax, ay := cellIndex(a[:precision])
+	bx, by := cellIndex(b[:precision])
+	x := (int64(bx) - int64(ax) + columns) % columns
+	if x > columns/2 {
+		x -= columns
+	}
+	return int(x), int(int64(by) - int64(ay))
+}
+
+// regionCells lists every cell with the given precision that intersects the region,
+// the empty geohash (the whole world) for precision 0
+func regionCells(r Region, precision int) []string {
+	if precision <= 0 {
+		return []string{""}
+	}
+	var cells []string
+	Grid(Encode(r.min.lat, r.min.lon, precision), Encode(r.max.lat, r.max.lon, precision), RowMajor)(func(cell string) bool {
+		cells = append(cells, cell)
+		return true
+	})
+	return cells
+}
@@ -0,0 +1,74 @@
+package geohash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCellBits(t *testing.T) {
+	lon, lat := cellBits(1)
+	assert.Equal(t, uint(3), lon)
+	assert.Equal(t, uint(2), lat)
+	lon, lat = cellBits(12)
+	assert.Equal(t, uint(30), lon)
+	assert.Equal(t, uint(30), lat)
+}
+
+func TestCellSize(t *testing.T) {
+	height, width := cellSize(1)
+	assert.Equal(t, 45.0, height)
+	assert.Equal(t, 45.0, width)
+	for _, v := range geohashTests {
+		r := Decode(v.geohash[:5])
+		height, width = cellSize(5)
+		assert.InDelta(t, r.Max().Latitude()-r.Min().Latitude(), height, 1e-12)
+		assert.InDelta(t, r.Max().Longitude()-r.Min().Longitude(), width, 1e-12)
+	}
+}
+
+func TestCellIndex(t *testing.T) {
+	x, y := cellIndex("0")
+	assert.Equal(t, uint64(0), x)
+	assert.Equal(t, uint64(0), y)
+	x, y = cellIndex("z")
+	assert.Equal(t, uint64(7), x)
+	assert.Equal(t, uint64(3), y)
+	// Round trip
+	for _, v := range geohashTests {
+		for i := 1; i <= len(v.geohash); i++ {
+			x, y := cellIndex(v.geohash[:i])
+			assert.Equal(t, v.geohash[:i], cellHash(x, y, i))
+		}
+	}
+}
+
+func TestRegionCells(t *testing.T) {
+	cells := regionCells(NewRegion(NewLocation(1, 1), NewLocation(2, 2)), 1)
+	assert.Equal(t, []string{"s"}, cells)
+	cells = regionCells(NewRegion(NewLocation(-1, -1), NewLocation(1, 1)), 1)
+	assert.ElementsMatch(t, []string{"7", "k", "e", "s"}, cells)
+	// The whole world
+	assert.Equal(t, []string{""}, regionCells(NewRegion(NewLocation(1, 1), NewLocation(2, 2)), 0))
+}
+
+func TestSteps(t *testing.T) {
+	dx, dy := Steps("9g3w8", "9g3w8")
+	assert.Equal(t, 0, dx)
+	assert.Equal(t, 0, dy)
+	for k, v := range map[string][2]int{"n": {0, 1}, "s": {0, -1}, "e": {1, 0}, "w": {-1, 0}, "ne": {1, 1}, "sw": {-1, -1}} {
+		dx, dy := Steps("9g3w8", Neighbours("9g3w8")[k])
+		assert.Equal(t, v[0], dx, k)
+		assert.Equal(t, v[1], dy, k)
+	}
+	// Shortest way across the antimeridian
+	dx, dy = Steps("x", "8")
+	assert.Equal(t, 1, dx)
+	assert.Equal(t, 0, dy)
+	dx, _ = Steps("8", "x")
+	assert.Equal(t, -1, dx)
+	// Different precisions are truncated
+	dx, dy = Steps("9g3w81t7", "0")
+	assert.Equal(t, -1, dx)
+	assert.Equal(t, -2, dy)
+}
@@ -0,0 +1,69 @@
+// Command countries-index builds the geohash index of the countries package
+// from a GeoJSON FeatureCollection of country polygons.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/phrozen/geohash"
+	"github.com/phrozen/geohash/countries"
+)
+
+// featureCollection is the subset of GeoJSON read from the input
+type featureCollection struct {
+	Features []struct {
+		Properties map[string]any  `json:"properties"`
+		Geometry   json.RawMessage `json:"geometry"`
+	} `json:"features"`
+}
+
+func main() {
+	in := flag.String("in", "", "GeoJSON FeatureCollection of country polygons")
+	out := flag.String("out", "countries.idx", "index file to write")
+	precision := flag.Int("precision", 5, "geohash precision of the cells at the borders")
+	properties := flag.String("properties", "ISO_A2,ISO_A2_EH", "feature properties holding the ISO 3166-1 alpha-2 code, first valid wins")
+	flag.Parse()
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		log.Fatalf("Error reading the GeoJSON file: %v", err)
+	}
+	var fc featureCollection
+	if err := json.Unmarshal(data, &fc); err != nil {
+		log.Fatalf("Error parsing the GeoJSON file: %v", err)
+	}
+	var features []countries.Feature
+	for _, f := range fc.Features {
+		code := ""
+		for _, name := range strings.Split(*properties, ",") {
+			if v, ok := f.Properties[name].(string); ok && len(v) == 2 {
+				code = strings.ToUpper(v)
+				break
+			}
+		}
+		if code == "" {
+			continue // disputed areas without a code
+		}
+		polygons, err := geohash.ParseGeoJSON(f.Geometry)
+		if err != nil {
+			log.Printf("Skipping %s: %v", code, err)
+			continue
+		}
+		features = append(features, countries.Feature{ISO2: code, Polygons: polygons})
+	}
+
+	idx := countries.Build(features, *precision)
+	file, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("Error creating the index file: %v", err)
+	}
+	defer file.Close()
+	if _, err := idx.WriteTo(file); err != nil {
+		log.Fatalf("Error writing the index file: %v", err)
+	}
+	log.Printf("Indexed %d countries in %d cells", len(features), idx.Len())
+}
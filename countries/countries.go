@@ -0,0 +1,185 @@
+// Package countries answers which country a location lies in without any network access,
+// from an embedded index of country boundaries covered with geohashes. The index is built
+// from countries.geojson, a simplified world map with a few dozen vertices per country that
+// is accurate to tens of kilometers, and is regenerated with:
+//
+//	go generate github.com/phrozen/geohash/countries
+//
+// The outlines in countries.geojson were traced by hand for this repository, not derived from
+// another dataset, and are distributed under its MIT license. Each of its 174 countries is a
+// polygon (or a few, for islands) with vertices rounded to 0.1 degrees, drawn on its own, so
+// neighbouring borders may overlap or leave gaps, and small countries and islands are missing.
+//
+// For sharper borders, build it from Natural Earth's admin 0 countries instead, downloaded
+// from https://www.naturalearthdata.com/downloads/ (ne_10m_admin_0_countries.geojson or the
+// 50m and 110m scales), with:
+//
+//	go run ./cmd/countries-index -in ne_10m_admin_0_countries.geojson -out countries.idx -precision 5
+package countries
+
+//go:generate go run ./cmd/countries-index -in countries.geojson -out countries.idx -precision 4
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed" // for the embedded index
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/phrozen/geohash"
+)
+
+// ErrInvalidIndex is returned when loading an index with an unknown format
+var ErrInvalidIndex = errors.New("countries: invalid index")
+
+// header starts every index file, followed by the precision of its boundary cells
+const header = "geohash-countries v1 precision="
+
+//go:embed countries.idx
+var embedded []byte
+
+var (
+	defaultIndex *Index
+	loadOnce     sync.Once
+)
+
+// Feature is a country and its polygons (islands, exclaves...)
+type Feature struct {
+	ISO2     string
+	Polygons []geohash.Polygon
+}
+
+// Index maps geohashes of any precision to the ISO 3166-1 alpha-2 code of the country
+// containing them. Cells inside a country are as coarse as possible, cells on borders
+// have the index precision and belong to the country containing their center.
+type Index struct {
+	precision int
+	cells     map[string]string
+}
+
+// CountryAt returns the ISO 3166-1 alpha-2 code of the country containing the location,
+// looked up in the embedded index. Returns false for locations in no country (the sea).
+func CountryAt(loc geohash.Location) (string, bool) {
+	loadOnce.Do(func() {
+		var err error
+		if defaultIndex, err = Load(bytes.NewReader(embedded)); err != nil {
+			panic(err) // the embedded index is generated, it can only be broken at build time
+		}
+	})
+	return defaultIndex.CountryAt(loc)
+}
+
+// Build covers the features' polygons with geohashes, with the given precision at the borders
+func Build(features []Feature, precision int) *Index {
+	idx := &Index{precision: precision, cells: make(map[string]string)}
+	for _, f := range features {
+		for _, p := range f.Polygons {
+			inside, boundary := geohash.CoverPolygon(p, precision)
+			for _, cell := range inside {
+				idx.cells[cell] = f.ISO2
+			}
+			for _, cell := range boundary {
+				if _, ok := idx.cells[cell]; !ok && p.Contains(geohash.Decode(cell).Center()) {
+					idx.cells[cell] = f.ISO2
+				}
+			}
+		}
+	}
+	idx.compact()
+	return idx
+}
+
+// Load reads an index written by WriteTo
+func Load(r io.Reader) (*Index, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	if !scanner.Scan() || !strings.HasPrefix(scanner.Text(), header) {
+		return nil, ErrInvalidIndex
+	}
+	precision, err := strconv.Atoi(strings.TrimPrefix(scanner.Text(), header))
+	if err != nil {
+		return nil, ErrInvalidIndex
+	}
+	idx := &Index{precision: precision, cells: make(map[string]string)}
+	// One line per country: the code followed by its cells
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		for _, cell := range fields[1:] {
+			if !geohash.Valid(cell) || len(cell) > precision {
+				return nil, ErrInvalidIndex
+			}
+			idx.cells[cell] = fields[0]
+		}
+	}
+	return idx, scanner.Err()
+}
+
+// WriteTo writes the index in a compact text format, one line per country
+func (idx *Index) WriteTo(w io.Writer) (int64, error) {
+	byCountry := make(map[string][]string)
+	for cell, code := range idx.cells {
+		byCountry[code] = append(byCountry[code], cell)
+	}
+	codes := make([]string, 0, len(byCountry))
+	for code := range byCountry {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	bw := bufio.NewWriter(w)
+	written := 0
+	n, _ := fmt.Fprintf(bw, "%s%d\n", header, idx.precision)
+	written += n
+	for _, code := range codes {
+		cells := byCountry[code]
+		sort.Strings(cells)
+		n, _ = fmt.Fprintf(bw, "%s %s\n", code, strings.Join(cells, " "))
+		written += n
+	}
+	return int64(written), bw.Flush()
+}
+
+// Len returns the number of cells in the index
+func (idx *Index) Len() int {
+	return len(idx.cells)
+}
+
+// CountryAt returns the code of the country containing the location, false if none
+func (idx *Index) CountryAt(loc geohash.Location) (string, bool) {
+	hash := geohash.Encode(loc.Latitude(), loc.Longitude(), idx.precision)
+	for i := 1; i <= len(hash); i++ {
+		if code, ok := idx.cells[hash[:i]]; ok {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// compact replaces every 32 sibling cells of the same country with their parent
+func (idx *Index) compact() {
+	for precision := idx.precision; precision > 1; precision-- {
+		siblings := make(map[string]int)
+		for cell, code := range idx.cells {
+			if len(cell) == precision {
+				siblings[cell[:precision-1]+" "+code]++
+			}
+		}
+		for key, count := range siblings {
+			if count < 32 {
+				continue
+			}
+			parent, code, _ := strings.Cut(key, " ")
+			for _, char := range geohash.Alphabet {
+				delete(idx.cells, parent+string(char))
+			}
+			idx.cells[parent] = code
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package countries
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/phrozen/geohash"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixture builds the features of testdata/fixture.geojson
+func fixture(t *testing.T) []Feature {
+	data, err := os.ReadFile("testdata/fixture.geojson")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var fc struct {
+		Features []struct {
+			Properties map[string]string `json:"properties"`
+			Geometry   json.RawMessage   `json:"geometry"`
+		} `json:"features"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &fc))
+	var features []Feature
+	for _, f := range fc.Features {
+		polygons, err := geohash.ParseGeoJSON(f.Geometry)
+		assert.NoError(t, err)
+		features = append(features, Feature{ISO2: f.Properties["ISO_A2"], Polygons: polygons})
+	}
+	return features
+}
+
+func TestBuild(t *testing.T) {
+	idx := Build(fixture(t), 4)
+	tests := map[geohash.Location]string{
+		geohash.NewLocation(2, 2):       "XA",
+		geohash.NewLocation(8, 9.5):     "XA",
+		geohash.NewLocation(30.5, 30.5): "XA", // island
+		geohash.NewLocation(2, 12):      "XB",
+		geohash.NewLocation(5, 14):      "XB",
+	}
+	for loc, code := range tests {
+		found, ok := idx.CountryAt(loc)
+		assert.True(t, ok, loc)
+		assert.Equal(t, code, found, loc)
+	}
+	// Sea, the hole and outside the triangle
+	for _, loc := range []geohash.Location{geohash.NewLocation(-5, -5), geohash.NewLocation(5, 5), geohash.NewLocation(9, 19)} {
+		_, ok := idx.CountryAt(loc)
+		assert.False(t, ok, loc)
+	}
+	// Compacted, no 32 siblings of the same country are left
+	siblings := make(map[string]int)
+	for cell, code := range idx.cells {
+		if len(cell) > 1 {
+			siblings[cell[:len(cell)-1]+code]++
+		}
+	}
+	for key, count := range siblings {
+		assert.Less(t, count, 32, key)
+	}
+}
+
+func TestWriteToAndLoad(t *testing.T) {
+	idx := Build(fixture(t), 4)
+	var buf bytes.Buffer
+	n, err := idx.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+	assert.True(t, strings.HasPrefix(buf.String(), "geohash-countries v1 precision=4\nXA "))
+	loaded, err := Load(&buf)
+	if assert.NoError(t, err) {
+		assert.Equal(t, idx, loaded)
+	}
+	// Invalid
+	for _, v := range []string{"", "countries\n", "geohash-countries v1 precision=x\n", "geohash-countries v1 precision=2\nXA abc\n", "geohash-countries v1 precision=2\nXA s0000\n"} {
+		_, err := Load(strings.NewReader(v))
+		assert.ErrorIs(t, err, ErrInvalidIndex, v)
+	}
+}
+
+func TestCountryAt(t *testing.T) {
+	tests := map[string]geohash.Location{
+		"MX": geohash.NewLocation(19.43265922422016, -99.13317967733457), // Mexico City
+		"FR": geohash.NewLocation(48.8566, 2.3522),                       // Paris
+		"DE": geohash.NewLocation(52.52, 13.405),                         // Berlin
+		"RU": geohash.NewLocation(55.7558, 37.6173),                      // Moscow
+		"CN": geohash.NewLocation(39.9042, 116.4074),                     // Beijing
+		"BR": geohash.NewLocation(-15.7939, -47.8828),                    // Brasília
+		"KE": geohash.NewLocation(-1.2921, 36.8219),                      // Nairobi
+		"AU": geohash.NewLocation(-35.2809, 149.13),                      // Canberra
+		"US": geohash.NewLocation(61.2181, -149.9003),                    // Anchorage
+		"LS": geohash.NewLocation(-29.3151, 27.4869),                     // Maseru, enclave in South Africa
+	}
+	for code, loc := range tests {
+		found, ok := CountryAt(loc)
+		assert.True(t, ok, code)
+		assert.Equal(t, code, found, loc)
+	}
+	// Open ocean
+	for _, loc := range []geohash.Location{geohash.NewLocation(0, -30), geohash.NewLocation(0, -140), geohash.NewLocation(-20, 80)} {
+		_, ok := CountryAt(loc)
+		assert.False(t, ok, loc)
+	}
+}
@@ -0,0 +1,213 @@
+package geohash
+
+import "math"
+
+// earthRadius is the mean radius of the Earth in meters
+const earthRadius = 6371008.8
+
+// Distance calculates the great-circle distance in meters between two locations
+// using the haversine formula.
+func Distance(a, b Location) float64 {
+	lat1, lat2 := radians(a.lat), radians(b.lat)
+	dLat := lat2 - lat1
+	dLon := radians(b.lon - a.lon)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(math.Min(1, h)))
+}
+
+// MinDistance calculates the distance in meters between the closest points of two geohashes,
+// it is 0 when they overlap or touch.
+func MinDistance(a, b string) float64 {
+	ra, rb := Decode(a), Decode(b)
+	if ra.Intersects(rb) {
+		return 0
+	}
+	distance := math.Inf(1)
+	for _, corner := range corners(ra) {
+		distance = math.Min(distance, regionDistance(rb, corner))
+	}
+	for _, corner := range corners(rb) {
+		distance = math.Min(distance, regionDistance(ra, corner))
+	}
+	return distance
+}
+
+// MaxDistance calculates the distance in meters between the farthest points of two geohashes,
+// sampled at the corners and middle of the edges of each.
+func MaxDistance(a, b string) float64 {
+	distance := 0.0
+	for _, p := range edgePoints(Decode(a)) {
+		for _, q := range edgePoints(Decode(b)) {
+			distance = math.Max(distance, Distance(p, q))
+		}
+	}
+	return distance
+}
+
+// CenterDistance calculates the distance in meters between the centers of two geohashes
+func CenterDistance(a, b string) float64 {
+	return Distance(Decode(a).Center(), Decode(b).Center())
+}
+
+// interpolate returns the location at fraction f (0..1) of the great-circle path from a to b
+func interpolate(a, b Location, f float64) Location {
+	delta := Distance(a, b) / earthRadius
+	if delta == 0 {
+		return a
+	}
+	lat1, lon1 := radians(a.lat), radians(a.lon)
+	lat2, lon2 := radians(b.lat), radians(b.lon)
+	wa := math.Sin((1-f)*delta) / math.Sin(delta)
+	wb := math.Sin(f*delta) / math.Sin(delta)
+	x := wa*math.Cos(lat1)*math.Cos(lon1) + wb*math.Cos(lat2)*math.Cos(lon2)
+	y := wa*math.Cos(lat1)*math.Sin(lon1) + wb*math.Cos(lat2)*math.Sin(lon2)
+	z := wa*math.Sin(lat1) + wb*math.Sin(lat2)
+	return NewLocation(degrees(math.Atan2(z, math.Hypot(x, y))), degrees(math.Atan2(y, x)))
+}
+
+// regionDistance calculates the distance in meters from loc to the closest point of the region,
+// it is 0 when the location lies inside the region.
+func regionDistance(r Region, loc Location) float64 {
+	lon := loc.lon
+	if !withinLongitude(r, lon) {
+		// Closest meridian edge, going around the antimeridian if shorter
+		west := math.Abs(wrapLongitude(r.min.lon - loc.lon))
+		east := math.Abs(wrapLongitude(loc.lon - r.max.lon))
+		lon = r.max.lon
+		if west < east {
+			lon = r.min.lon
+		}
+	}
+	// Foot of the perpendicular from loc to the meridian edge (great circle), poleward of loc
+	lat := loc.lat
+	if dLon := math.Abs(wrapLongitude(lon - loc.lon)); dLon >= 90 {
+		lat = math.Copysign(90, loc.lat)
+	} else if dLon > 0 {
+		lat = degrees(math.Atan2(math.Tan(radians(loc.lat)), math.Cos(radians(dLon))))
+	}
+	lat = math.Max(r.min.lat, math.Min(r.max.lat, lat))
+	return Distance(loc, NewLocation(lat, lon))
+}
+
+// segmentDistance calculates the distance in meters from the region to the closest point of the
+// great-circle segment from a to b, it is 0 when the segment passes through the region.
+func segmentDistance(r Region, a, b Location) float64 {
+	distance := min(regionDistance(r, a), regionDistance(r, b))
+	// Outside the longitudes of the region the closest points are its corners or the ends
+	for _, corner := range corners(r) {
+		distance = min(distance, pointSegmentDistance(corner, a, b))
+	}
+	// Within them, the latitude gap between the segment and the region
+	if lo, hi, ok := segmentLatitudes(a, b, r); ok {
+		distance = min(distance, radians(max(r.min.lat-hi, lo-r.max.lat, 0))*earthRadius)
+	}
+	return distance
+}
+
+// pointSegmentDistance calculates the distance in meters from loc to the closest point of the
+// great-circle segment from a to b: its cross-track distance when the foot of the perpendicular
+// lies on the segment, otherwise the distance to the nearest end.
+func pointSegmentDistance(loc, a, b Location) float64 {
+	p, n := vector(loc), cross(vector(a), vector(b))
+	if norm := math.Sqrt(dot(n, n)); norm > 0 {
+		sin := dot(p, n) / norm
+		foot := [3]float64{p[0] - sin*n[0]/norm, p[1] - sin*n[1]/norm, p[2] - sin*n[2]/norm}
+		if dot(foot, foot) > 0 && onSegment(foot, a, b) {
+			return earthRadius * math.Asin(math.Min(1, math.Abs(sin)))
+		}
+	}
+	return min(Distance(loc, a), Distance(loc, b))
+}
+
+// segmentLatitudes calculates the lowest and highest latitudes of the great-circle segment from
+// a to b between the West and East edges of the region, ok is false when it does not reach them.
+// Those are at its ends, where it crosses the edges or at the poleward vertex of its great circle.
+func segmentLatitudes(a, b Location, r Region) (lo, hi float64, ok bool) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	add := func(v [3]float64) {
+		lat := degrees(math.Atan2(v[2], math.Hypot(v[0], v[1])))
+		lo, hi, ok = min(lo, lat), max(hi, lat), true
+	}
+	for _, loc := range []Location{a, b} {
+		if withinLongitude(r, loc.lon) {
+			add(vector(loc))
+		}
+	}
+	n := cross(vector(a), vector(b))
+	norm := math.Sqrt(dot(n, n))
+	if norm == 0 {
+		return lo, hi, ok
+	}
+	for _, lon := range []float64{r.min.lon, r.max.lon} {
+		// The great circle meets the meridian plane along their common line, on the meridian's side
+		meridian := [3]float64{math.Cos(radians(lon)), math.Sin(radians(lon)), 0}
+		v := cross(n, [3]float64{-meridian[1], meridian[0], 0})
+		if dot(v, meridian) < 0 {
+			v = [3]float64{-v[0], -v[1], -v[2]}
+		}
+		if dot(v, v) > 0 && onSegment(v, a, b) {
+			add(v)
+		}
+	}
+	// The points of the great circle closest to the poles
+	vertex := [3]float64{-n[2] * n[0] / norm / norm, -n[2] * n[1] / norm / norm, 1 - n[2]*n[2]/norm/norm}
+	for _, v := range [][3]float64{vertex, {-vertex[0], -vertex[1], -vertex[2]}} {
+		if dot(v, v) > 0 && onSegment(v, a, b) && withinLongitude(r, degrees(math.Atan2(v[1], v[0]))) {
+			add(v)
+		}
+	}
+	return lo, hi, ok
+}
+
+// onSegment checks if the vector, lying on the great circle through a and b, is between them
+func onSegment(v [3]float64, a, b Location) bool {
+	va, vb := vector(a), vector(b)
+	n := cross(va, vb)
+	return dot(cross(va, v), n) >= 0 && dot(cross(v, vb), n) >= 0
+}
+
+// vector returns the unit vector from the center of the Earth to the location
+func vector(loc Location) [3]float64 {
+	lat, lon := radians(loc.lat), radians(loc.lon)
+	return [3]float64{math.Cos(lat) * math.Cos(lon), math.Cos(lat) * math.Sin(lon), math.Sin(lat)}
+}
+
+func cross(a, b [3]float64) [3]float64 {
+	return [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+func dot(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+// corners returns the 4 corners of the region: SW, NW, NE, SE
+func corners(r Region) []Location {
+	return []Location{r.min, NewLocation(r.max.lat, r.min.lon), r.max, NewLocation(r.min.lat, r.max.lon)}
+}
+
+// edgePoints returns the corners of the region and the middle points of its edges
+func edgePoints(r Region) []Location {
+	c := r.Center()
+	return append(corners(r),
+		NewLocation(r.min.lat, c.lon), NewLocation(r.max.lat, c.lon),
+		NewLocation(c.lat, r.min.lon), NewLocation(c.lat, r.max.lon),
+	)
+}
+
+// withinLongitude checks if the longitude lies between the region's west and east edges
+func withinLongitude(r Region, lon float64) bool {
+	return lon >= r.min.lon && lon <= r.max.lon
+}
+
+// wrapLongitude normalizes a longitude difference into the range [-180, 180)
+func wrapLongitude(lon float64) float64 {
+	return math.Mod(math.Mod(lon+180, 360)+360, 360) - 180
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
@@ -0,0 +1,74 @@
+package geohash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	// Same location
+	assert.Equal(t, 0.0, Distance(NewLocation(10, 10), NewLocation(10, 10)))
+	// One degree of latitude is ~111.2km
+	assert.InDelta(t, 111195.0, Distance(NewLocation(0, 0), NewLocation(1, 0)), 1)
+	// Across the antimeridian
+	assert.InDelta(t, 222390.0, Distance(NewLocation(0, 179), NewLocation(0, -179)), 1)
+	// Vatican to Moscow ~2375km
+	vatican, moscow := NewLocation(41.90216070037718, 12.453725061736066), NewLocation(55.753730934309345, 37.61990186254636)
+	assert.InDelta(t, 2375000.0, Distance(vatican, moscow), 5000)
+	assert.Equal(t, Distance(vatican, moscow), Distance(moscow, vatican))
+}
+
+func TestInterpolate(t *testing.T) {
+	a, b := NewLocation(0, 0), NewLocation(0, 90)
+	assert.Equal(t, a, interpolate(a, a, 0.5))
+	mid := interpolate(a, b, 0.5)
+	assert.InDelta(t, 0.0, mid.Latitude(), 1e-9)
+	assert.InDelta(t, 45.0, mid.Longitude(), 1e-9)
+	end := interpolate(a, b, 1)
+	assert.InDelta(t, 90.0, end.Longitude(), 1e-9)
+}
+
+func TestRegionDistance(t *testing.T) {
+	r := NewRegion(NewLocation(0, 0), NewLocation(10, 10))
+	// Inside
+	assert.Equal(t, 0.0, regionDistance(r, NewLocation(5, 5)))
+	// North and South
+	assert.InDelta(t, Distance(NewLocation(11, 5), NewLocation(10, 5)), regionDistance(r, NewLocation(11, 5)), 1e-6)
+	assert.InDelta(t, Distance(NewLocation(-1, 5), NewLocation(0, 5)), regionDistance(r, NewLocation(-1, 5)), 1e-6)
+	// East, the closest point of a meridian lies poleward
+	assert.Less(t, regionDistance(r, NewLocation(5, 12)), Distance(NewLocation(5, 12), NewLocation(5, 10)))
+	// West across the antimeridian
+	assert.InDelta(t, Distance(NewLocation(0, 179), NewLocation(0, 180)), regionDistance(NewRegion(NewLocation(-10, -180), NewLocation(10, -170)), NewLocation(0, 179)), 1e-6)
+}
+
+func TestWrapLongitude(t *testing.T) {
+	assert.Equal(t, 0.0, wrapLongitude(360))
+	assert.Equal(t, -179.0, wrapLongitude(181))
+	assert.Equal(t, 179.0, wrapLongitude(-181))
+	assert.Equal(t, -180.0, wrapLongitude(180))
+}
+
+func TestCellDistances(t *testing.T) {
+	// Same and adjacent cells
+	assert.Equal(t, 0.0, MinDistance("9g3w8", "9g3w8"))
+	assert.Equal(t, 0.0, MinDistance("9g3w8", "9g3w9"))
+	assert.Equal(t, 0.0, CenterDistance("9g3w8", "9g3w8"))
+	// Adjacent across the antimeridian
+	assert.InDelta(t, 0.0, MinDistance("8", "x"), 1e-6)
+	// One cell apart, the gap is the height of a cell
+	height, _ := cellSize(5)
+	x, y := cellIndex("9g3w8")
+	assert.InDelta(t, radians(height)*earthRadius, MinDistance("9g3w8", cellHash(x, y+2, 5)), 1)
+	// Ordering between the three metrics
+	for _, pair := range [][2]string{{"9g3w8", "9g3wc"}, {"sr2y", "ucfv"}, {"3e4m", "r3gx"}, {"9", "u"}} {
+		min, center, max := MinDistance(pair[0], pair[1]), CenterDistance(pair[0], pair[1]), MaxDistance(pair[0], pair[1])
+		assert.Less(t, min, center)
+		assert.Less(t, center, max)
+		assert.Equal(t, min, MinDistance(pair[1], pair[0]))
+		assert.Equal(t, max, MaxDistance(pair[1], pair[0]))
+	}
+	// Diagonal of a single cell
+	r := Decode("9g3w8")
+	assert.InDelta(t, Distance(r.Min(), r.Max()), MaxDistance("9g3w8", "9g3w8"), 1)
+}
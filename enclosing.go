@@ -0,0 +1,104 @@
+package geohash
+
+import "sort"
+
+// CommonPrefix returns the longest prefix shared by all the geohashes,
+// which is the smallest single cell containing all of them.
+func CommonPrefix(hashes ...string) string {
+	if len(hashes) == 0 {
+		return ""
+	}
+	prefix := hashes[0]
+	for _, geohash := range hashes[1:] {
+		i := 0
+		for i < len(prefix) && i < len(geohash) && prefix[i] == geohash[i] {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	return prefix
+}
+
+// Enclosing returns the smallest single geohash containing all the locations. It is empty
+// when the locations straddle the boundary of a top level cell, see EnclosingCells.
+func Enclosing(locs ...Location) string {
+	hashes := make([]string, len(locs))
+	for i, loc := range locs {
+		hashes[i] = Encode(loc.lat, loc.lon, indexPrecision)
+	}
+	return CommonPrefix(hashes...)
+}
+
+// EnclosingCells returns a minimal set of (up to 4) adjacent geohashes with the same precision
+// containing all the locations, choosing the finest precision at which they fit in a block of
+// 2 by 2 cells. Locations too far apart for that return all the top level cells they lie in.
+func EnclosingCells(locs ...Location) []string {
+	if len(locs) == 0 {
+		return nil
+	}
+	var cells []string
+	for precision := indexPrecision; precision >= 1; precision-- {
+		seen := make(map[string]bool)
+		cells = cells[:0]
+		for _, loc := range locs {
+			if geohash := Encode(loc.lat, loc.lon, precision); !seen[geohash] {
+				seen[geohash] = true
+				cells = append(cells, geohash)
+			}
+		}
+		if fitsBlock(cells) {
+			break
+		}
+	}
+	sort.Strings(cells)
+	return cells
+}
+
+// BoundingRegion returns the bounding box of all the geohashes' regions
+func BoundingRegion(hashes ...string) Region {
+	if len(hashes) == 0 {
+		return Region{}
+	}
+	bounds := Decode(hashes[0])
+	for _, geohash := range hashes[1:] {
+		r := Decode(geohash)
+		bounds = NewRegion(
+			NewLocation(min(bounds.min.lat, r.min.lat), min(bounds.min.lon, r.min.lon)),
+			NewLocation(max(bounds.max.lat, r.max.lat), max(bounds.max.lon, r.max.lon)),
+		)
+	}
+	return bounds
+}
+
+// fitsBlock checks if the cells (same precision) are within a block of 2 by 2 adjacent cells,
+// columns being adjacent across the antimeridian too.
+func fitsBlock(cells []string) bool {
+	if len(cells) > 4 {
+		return false
+	}
+	lonBits, _ := cellBits(len(cells[0]))
+	columns := uint64(1) << lonBits
+	var xs, ys []uint64
+	for _, cell := range cells {
+		x, y := cellIndex(cell)
+		xs, ys = appendUnique(xs, x), appendUnique(ys, y)
+	}
+	if len(xs) > 2 || len(ys) > 2 {
+		return false
+	}
+	if len(xs) == 2 {
+		if dx := (xs[0] - xs[1] + columns) % columns; dx != 1 && dx != columns-1 {
+			return false
+		}
+	}
+	return len(ys) < 2 || ys[0]-ys[1] == 1 || ys[1]-ys[0] == 1
+}
+
+func appendUnique(values []uint64, v uint64) []uint64 {
+	for _, u := range values {
+		if u == v {
+			return values
+		}
+	}
+	return append(values, v)
+}
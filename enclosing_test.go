@@ -0,0 +1,65 @@
+package geohash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommonPrefix(t *testing.T) {
+	assert.Equal(t, "", CommonPrefix())
+	assert.Equal(t, "9g3w81t7mqpx", CommonPrefix("9g3w81t7mqpx"))
+	assert.Equal(t, "9g3w", CommonPrefix("9g3w81t7mqpx", "9g3wb", "9g3w"))
+	assert.Equal(t, "", CommonPrefix("9g3w81t7mqpx", "sr2y7kh9bbfk"))
+	assert.Equal(t, "", CommonPrefix("9g3w", ""))
+}
+
+func TestEnclosing(t *testing.T) {
+	assert.Equal(t, "", Enclosing())
+	a, b := NewLocation(19.43265922422016, -99.13317967733457), NewLocation(19.44, -99.14)
+	assert.Equal(t, "9g3w81t7mqpx", Enclosing(a))
+	prefix := Enclosing(a, b)
+	assert.Equal(t, "9g3w8", prefix)
+	assert.True(t, Decode(prefix).Contains(a))
+	assert.True(t, Decode(prefix).Contains(b))
+	// Straddling the equator and the prime meridian
+	assert.Equal(t, "", Enclosing(NewLocation(0.0001, 0.0001), NewLocation(-0.0001, -0.0001)))
+}
+
+func TestEnclosingCells(t *testing.T) {
+	assert.Nil(t, EnclosingCells())
+	a := NewLocation(19.43265922422016, -99.13317967733457)
+	assert.Equal(t, []string{"9g3w81t7mqpx"}, EnclosingCells(a))
+	// Straddling the equator and the prime meridian
+	cells := EnclosingCells(NewLocation(0.0001, 0.0001), NewLocation(-0.0001, -0.0001))
+	if assert.Len(t, cells, 2) {
+		assert.Equal(t, len(cells[0]), len(cells[1]))
+		assert.Greater(t, len(cells[0]), 4)
+		assert.Equal(t, "7", cells[0][:1])
+		assert.Equal(t, "s", cells[1][:1])
+	}
+	// All four quadrants
+	cells = EnclosingCells(NewLocation(0.01, 0.01), NewLocation(-0.01, -0.01), NewLocation(0.01, -0.01), NewLocation(-0.01, 0.01))
+	assert.Len(t, cells, 4)
+	// Across the antimeridian
+	cells = EnclosingCells(NewLocation(10, 179.99), NewLocation(10, -179.99))
+	if assert.Len(t, cells, 2) {
+		assert.Equal(t, "8", cells[0][:1])
+		assert.Equal(t, "x", cells[1][:1])
+	}
+	// Far apart, top level cells
+	var locs []Location
+	for _, v := range geohashTests {
+		locs = append(locs, NewLocation(v.latitude, v.longitude))
+	}
+	assert.Equal(t, []string{"3", "9", "r", "s", "u"}, EnclosingCells(locs...))
+}
+
+func TestBoundingRegion(t *testing.T) {
+	assert.Equal(t, Region{}, BoundingRegion())
+	assert.Equal(t, Decode("9g3w"), BoundingRegion("9g3w"))
+	r := BoundingRegion("9", "d")
+	assert.Equal(t, NewRegion(Decode("9").Min(), Decode("d").Max()), r)
+	r = BoundingRegion("9g3w8", "9g3w", "9g3wb")
+	assert.Equal(t, Decode("9g3w"), r)
+}
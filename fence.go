@@ -0,0 +1,118 @@
+package geohash
+
+import (
+	"sort"
+	"sync"
+)
+
+// FenceID identifies a fence in a FenceSet
+type FenceID string
+
+// FenceSet is a collection of polygons (geofences) indexed by their geohash cover,
+// to find the ones containing a location without testing each of them. It is
+// safe for concurrent readers with a single writer.
+type FenceSet struct {
+	mu        sync.RWMutex
+	precision int
+	fences    map[FenceID]fence
+	inside    map[string][]FenceID // cells (any precision) completely inside fences
+	boundary  map[string][]FenceID // cells (set precision) crossed by the fences' boundary
+}
+
+// fence is a polygon and the cells covering it
+type fence struct {
+	polygon          Polygon
+	inside, boundary []string
+}
+
+// NewFenceSet creates an empty FenceSet, covering the fences with geohashes of the given
+// precision, at least 1. Finer precisions use more memory but run fewer exact polygon tests.
+func NewFenceSet(precision int) *FenceSet {
+	return &FenceSet{
+		precision: max(precision, 1),
+		fences:    make(map[FenceID]fence),
+		inside:    make(map[string][]FenceID),
+		boundary:  make(map[string][]FenceID),
+	}
+}
+
+// Len returns the number of fences in the set
+func (fs *FenceSet) Len() int {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	return len(fs.fences)
+}
+
+// Add inserts a fence into the set, replacing the one with the same id if any
+func (fs *FenceSet) Add(id FenceID, p Polygon) {
+	// Covering is the expensive part, done before taking the lock
+	inside, boundary := CoverPolygon(p, fs.precision)
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.remove(id)
+	fs.fences[id] = fence{polygon: p, inside: inside, boundary: boundary}
+	for _, cell := range inside {
+		fs.inside[cell] = append(fs.inside[cell], id)
+	}
+	for _, cell := range boundary {
+		fs.boundary[cell] = append(fs.boundary[cell], id)
+	}
+}
+
+// Remove deletes a fence from the set, returns false if it was not found
+func (fs *FenceSet) Remove(id FenceID) bool {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.remove(id)
+}
+
+// Lookup returns the ids (sorted) of all the fences containing the location.
+// Only fences whose boundary crosses the location's cell are tested exactly.
+func (fs *FenceSet) Lookup(loc Location) []FenceID {
+	geohash := Encode(loc.lat, loc.lon, fs.precision)
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	var ids []FenceID
+	for i := 1; i <= len(geohash); i++ {
+		ids = append(ids, fs.inside[geohash[:i]]...)
+	}
+	for _, id := range fs.boundary[geohash] {
+		if fs.fences[id].polygon.Contains(loc) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// remove deletes the fence and its cells, the lock must be held
+func (fs *FenceSet) remove(id FenceID) bool {
+	f, ok := fs.fences[id]
+	if !ok {
+		return false
+	}
+	for _, cell := range f.inside {
+		fs.inside[cell] = without(fs.inside[cell], id)
+		if len(fs.inside[cell]) == 0 {
+			delete(fs.inside, cell)
+		}
+	}
+	for _, cell := range f.boundary {
+		fs.boundary[cell] = without(fs.boundary[cell], id)
+		if len(fs.boundary[cell]) == 0 {
+			delete(fs.boundary, cell)
+		}
+	}
+	delete(fs.fences, id)
+	return true
+}
+
+// without returns the ids without the given one
+func without(ids []FenceID, id FenceID) []FenceID {
+	for i, v := range ids {
+		if v == id {
+			return append(ids[:i:i], ids[i+1:]...)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,81 @@
+package geohash
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFenceSet(t *testing.T) {
+	fs := NewFenceSet(5)
+	fs.Add("city", NewPolygon(square(19.3, -99.3, 19.6, -99.0)))
+	fs.Add("downtown", NewPolygon(square(19.42, -99.15, 19.45, -99.12)))
+	fs.Add("donut", NewPolygon(square(19.3, -99.3, 19.6, -99.0), square(19.4, -99.2, 19.5, -99.1)))
+	assert.Equal(t, 3, fs.Len())
+	zocalo := NewLocation(19.43265922422016, -99.13317967733457)
+	assert.Equal(t, []FenceID{"city", "downtown"}, fs.Lookup(zocalo))
+	assert.Equal(t, []FenceID{"city", "donut"}, fs.Lookup(NewLocation(19.35, -99.25)))
+	assert.Empty(t, fs.Lookup(NewLocation(41.90216070037718, 12.453725061736066)))
+	// Against an exact test of every fence
+	fences := map[FenceID]Polygon{}
+	for id, f := range fs.fences {
+		fences[id] = f.polygon
+	}
+	for lat := 19.25; lat < 19.65; lat += 0.007 {
+		for lon := -99.35; lon < -98.95; lon += 0.007 {
+			loc := NewLocation(lat, lon)
+			var expected []FenceID
+			for _, id := range []FenceID{"city", "donut", "downtown"} {
+				if fences[id].Contains(loc) {
+					expected = append(expected, id)
+				}
+			}
+			assert.Equal(t, expected, fs.Lookup(loc))
+		}
+	}
+	// Replace and remove
+	fs.Add("downtown", NewPolygon(square(19.35, -99.26, 19.36, -99.24)))
+	assert.Equal(t, []FenceID{"city"}, fs.Lookup(zocalo))
+	assert.True(t, fs.Remove("city"))
+	assert.False(t, fs.Remove("city"))
+	assert.Empty(t, fs.Lookup(zocalo))
+	assert.True(t, fs.Remove("downtown"))
+	assert.True(t, fs.Remove("donut"))
+	assert.Equal(t, 0, fs.Len())
+	assert.Empty(t, fs.inside)
+	assert.Empty(t, fs.boundary)
+}
+
+func TestFenceSetPrecision(t *testing.T) {
+	// Precisions below 1 are raised to 1, so fences are still found
+	for _, precision := range []int{0, -1} {
+		fs := NewFenceSet(precision)
+		fs.Add("square", NewPolygon(square(0, 0, 10, 10)))
+		assert.Equal(t, []FenceID{"square"}, fs.Lookup(NewLocation(5, 5)), precision)
+		assert.Empty(t, fs.Lookup(NewLocation(-5, 5)), precision)
+	}
+}
+
+func TestFenceSetConcurrency(t *testing.T) {
+	fs := NewFenceSet(4)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			fs.Add(FenceID(rune('a'+i)), NewPolygon(square(float64(i), 0, float64(i)+1, 1)))
+		}
+	}()
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				fs.Lookup(NewLocation(float64(i%20)+0.5, 0.5))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, []FenceID{"f"}, fs.Lookup(NewLocation(5.5, 0.5)))
+}
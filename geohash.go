@@ -7,9 +7,13 @@ import (
 	"math"
 )
 
+// Alphabet is the base32 dictionary of geohash characters, in the order of their values
+// so geohashes sort like the cells they encode
+const Alphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
 var (
 	//Base32 is the dictionary of characters for generating hashes
-	base32 = []byte("0123456789bcdefghjkmnpqrstuvwxyz")
+	base32 = []byte(Alphabet)
 	// Bitmask positions for 5 bit base32 encoding
 	// []int{ 0b10000, 0b01000, 0b00100, 0b00010, 0b00001 }
 	bits = []int{16, 8, 4, 2, 1}
@@ -61,6 +65,17 @@ func (r Region) Center() Location {
 	return NewLocation((r.min.lat+r.max.lat)/2, (r.min.lon+r.max.lon)/2)
 }
 
+// Contains checks if the location lies inside the region (edges included)
+func (r Region) Contains(loc Location) bool {
+	return loc.lat >= r.min.lat && loc.lat <= r.max.lat && loc.lon >= r.min.lon && loc.lon <= r.max.lon
+}
+
+// Intersects checks if the region overlaps (or touches) another region
+func (r Region) Intersects(other Region) bool {
+	return r.min.lat <= other.max.lat && r.max.lat >= other.min.lat &&
+		r.min.lon <= other.max.lon && r.max.lon >= other.min.lon
+}
+
 // Encode a latitude/longitude pair into a geohash with the given precision.
 func Encode(latitude, longitude float64, precision int) string {
 	minLatitude, maxLatitude := -90.0, 90.0
@@ -168,6 +183,17 @@ func Valid(geohash string) bool {
 	return true
 }
 
+// PrefixEnd returns the first geohash after every geohash starting with prefix in sort order,
+// empty when there is none. Keys starting with prefix lie in [prefix, PrefixEnd(prefix)).
+func PrefixEnd(prefix string) string {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if j := base32Index(prefix[i]); j < len(base32)-1 {
+			return prefix[:i] + string(base32[j+1])
+		}
+	}
+	return ""
+}
+
 // Rotates the map for out of bound coordinates
 func fixOutOfBounds(num, min, max float64) float64 {
 	if num < min {
@@ -0,0 +1,147 @@
+package geohash
+
+import (
+	"bytes"
+	"math"
+)
+
+// Space3D is the altitude range (in meters) of 3D geohashes. They split space like an octree,
+// interleaving longitude, latitude and altitude bits in that order.
+type Space3D struct {
+	minAltitude, maxAltitude float64
+}
+
+// defaultSpace3D goes from below the Dead Sea shore to well above airliner cruising altitude
+var defaultSpace3D = NewSpace3D(-500, 15500)
+
+// NewSpace3D defines a 3D geohash space for altitudes between min and max meters
+func NewSpace3D(minAltitude, maxAltitude float64) Space3D {
+	return Space3D{minAltitude: minAltitude, maxAltitude: maxAltitude}
+}
+
+// Box is a region with an altitude range, the 3D representation of an area
+type Box struct {
+	region                   Region
+	minAltitude, maxAltitude float64
+}
+
+// NewBox defines a new box over the region between min and max altitudes
+func NewBox(region Region, minAltitude, maxAltitude float64) Box {
+	return Box{region: region, minAltitude: minAltitude, maxAltitude: maxAltitude}
+}
+
+// Region returns the bounding box of the Box on the surface
+func (b Box) Region() Region {
+	return b.region
+}
+
+// MinAltitude returns the bottom altitude of the Box
+func (b Box) MinAltitude() float64 {
+	return b.minAltitude
+}
+
+// MaxAltitude returns the top altitude of the Box
+func (b Box) MaxAltitude() float64 {
+	return b.maxAltitude
+}
+
+// Center returns the mid point location and altitude of the Box
+func (b Box) Center() (Location, float64) {
+	return b.region.Center(), (b.minAltitude + b.maxAltitude) / 2
+}
+
+// Encode3D a latitude/longitude/altitude into a 3D geohash with the given precision,
+// with altitudes between -500 and 15500 meters.
+func Encode3D(latitude, longitude, altitude float64, precision int) string {
+	return defaultSpace3D.Encode(latitude, longitude, altitude, precision)
+}
+
+// Decode3D a 3D geohash into a box, with altitudes between -500 and 15500 meters
+func Decode3D(geohash string) Box {
+	return defaultSpace3D.Decode(geohash)
+}
+
+// Neighbours3D calculates the 26 adjacent neighbouring 3D geohashes with the same precision,
+// with altitudes between -500 and 15500 meters.
+func Neighbours3D(geohash string) map[string]string {
+	return defaultSpace3D.Neighbours(geohash)
+}
+
+// Encode a latitude/longitude/altitude into a 3D geohash with the given precision.
+// Altitudes out of the space's range are clamped to it.
+func (s Space3D) Encode(latitude, longitude, altitude float64, precision int) string {
+	min := []float64{-180, -90, s.minAltitude}
+	max := []float64{180, 90, s.maxAltitude}
+	value := []float64{
+		fixOutOfBounds(longitude, min[0], max[0]),
+		fixOutOfBounds(latitude, min[1], max[1]),
+		math.Max(s.minAltitude, math.Min(s.maxAltitude, altitude)),
+	}
+	char, bit, dim := 0, 0, 0 // dim cycles LONGITUDE, LATITUDE, ALTITUDE
+	var geohash bytes.Buffer
+	for geohash.Len() < precision {
+		mid := (min[dim] + max[dim]) / 2
+		if value[dim] > mid {
+			char |= bits[bit]
+			min[dim] = mid
+		} else {
+			max[dim] = mid
+		}
+		dim = (dim + 1) % 3
+		// Every 5 bits, encode a character and reset
+		if bit < 4 {
+			bit++
+		} else {
+			geohash.WriteByte(base32[char])
+			char, bit = 0, 0
+		}
+	}
+	return geohash.String()
+}
+
+// Decode a 3D geohash into a box
+func (s Space3D) Decode(geohash string) Box {
+	min := []float64{-180, -90, s.minAltitude}
+	max := []float64{180, 90, s.maxAltitude}
+	dim := 0
+	for _, char := range []byte(geohash) {
+		decimal := base32Index(char)
+		for _, mask := range bits {
+			if decimal&mask != 0 {
+				min[dim] = (min[dim] + max[dim]) / 2
+			} else {
+				max[dim] = (min[dim] + max[dim]) / 2
+			}
+			dim = (dim + 1) % 3
+		}
+	}
+	return NewBox(NewRegion(NewLocation(min[1], min[0]), NewLocation(max[1], max[0])), min[2], max[2])
+}
+
+// Neighbours calculates the (up to) 26 adjacent neighbouring 3D geohashes with the same precision.
+// Keys are the compass directions of Neighbours, "u" (up) and "d" (down), and their combinations
+// such as "nu" or "swd". Neighbours above or below the altitude range are left out.
+func (s Space3D) Neighbours(geohash string) map[string]string {
+	box := s.Decode(geohash)
+	region := box.Region()
+	width := region.Max().Longitude() - region.Min().Longitude()
+	height := region.Max().Latitude() - region.Min().Latitude()
+	depth := box.MaxAltitude() - box.MinAltitude()
+	center, altitude := box.Center()
+	precision := len(geohash)
+	horizontal := map[string][2]float64{
+		"": {0, 0}, "n": {height, 0}, "s": {-height, 0}, "e": {0, width}, "w": {0, -width},
+		"ne": {height, width}, "se": {-height, width}, "sw": {-height, -width}, "nw": {height, -width},
+	}
+	vertical := map[string]float64{"": 0, "u": depth, "d": -depth}
+	neighbours := make(map[string]string, 26)
+	for h, dh := range horizontal {
+		for v, dv := range vertical {
+			if h+v == "" || altitude+dv < s.minAltitude || altitude+dv > s.maxAltitude {
+				continue
+			}
+			neighbours[h+v] = s.Encode(center.Latitude()+dh[0], center.Longitude()+dh[1], altitude+dv, precision)
+		}
+	}
+	return neighbours
+}
@@ -0,0 +1,75 @@
+package geohash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeDecode3D(t *testing.T) {
+	for _, v := range geohashTests {
+		for _, altitude := range []float64{-400, 0, 120.5, 9000, 15000} {
+			for precision := 1; precision <= 12; precision++ {
+				geohash := Encode3D(v.latitude, v.longitude, altitude, precision)
+				assert.Len(t, geohash, precision)
+				assert.True(t, Valid(geohash))
+				box := Decode3D(geohash)
+				assert.True(t, box.Region().Contains(NewLocation(v.latitude, v.longitude)))
+				assert.GreaterOrEqual(t, altitude, box.MinAltitude())
+				assert.LessOrEqual(t, altitude, box.MaxAltitude())
+				// Prefix property, every character refines the box
+				assert.Equal(t, geohash[:precision-1], Encode3D(v.latitude, v.longitude, altitude, precision-1))
+			}
+		}
+	}
+	// Single character: 2 longitude, 2 latitude and 1 altitude bits
+	box := Decode3D("0")
+	assert.Equal(t, NewRegion(NewLocation(-90, -180), NewLocation(-45, -90)), box.Region())
+	assert.Equal(t, -500.0, box.MinAltitude())
+	assert.Equal(t, 7500.0, box.MaxAltitude())
+	// Out of range altitudes are clamped
+	assert.Equal(t, Encode3D(0, 0, 15500, 8), Encode3D(0, 0, 99999, 8))
+	assert.Equal(t, Encode3D(0, 0, -500, 8), Encode3D(0, 0, -99999, 8))
+}
+
+func TestSpace3D(t *testing.T) {
+	space := NewSpace3D(0, 1000)
+	geohash := space.Encode(19.43, -99.13, 250, 10)
+	box := space.Decode(geohash)
+	center, altitude := box.Center()
+	assert.InDelta(t, 19.43, center.Latitude(), 0.002)
+	assert.InDelta(t, -99.13, center.Longitude(), 0.002)
+	assert.InDelta(t, 250, altitude, 1)
+	// Different space, different key
+	assert.NotEqual(t, Encode3D(19.43, -99.13, 250, 10), geohash)
+}
+
+func TestNeighbours3D(t *testing.T) {
+	space := NewSpace3D(0, 1000)
+	geohash := space.Encode(19.43, -99.13, 500, 9)
+	neighbours := space.Neighbours(geohash)
+	assert.Len(t, neighbours, 26)
+	seen := map[string]bool{geohash: true}
+	box := space.Decode(geohash)
+	for k, v := range neighbours {
+		assert.False(t, seen[v], "Neighbours should be unique")
+		seen[v] = true
+		n := space.Decode(v)
+		// Vertical direction
+		switch k[len(k)-1] {
+		case 'u':
+			assert.Equal(t, box.MaxAltitude(), n.MinAltitude(), k)
+		case 'd':
+			assert.Equal(t, box.MinAltitude(), n.MaxAltitude(), k)
+		default:
+			assert.Equal(t, box.MinAltitude(), n.MinAltitude(), k)
+		}
+		assert.True(t, n.Region().Intersects(box.Region()), k)
+	}
+	// At the bottom of the range there is nothing below
+	neighbours = Neighbours3D(Encode3D(19.43, -99.13, -500, 6))
+	assert.Len(t, neighbours, 17)
+	for k := range neighbours {
+		assert.NotEqual(t, byte('d'), k[len(k)-1])
+	}
+}
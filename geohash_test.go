@@ -43,6 +43,17 @@ func TestRegion(t *testing.T) {
 	// Min, Max
 	assert.Equal(t, min, r.Min())
 	assert.Equal(t, max, r.Max())
+	// Contains
+	small := NewRegion(NewLocation(0, 0), NewLocation(10, 10))
+	assert.True(t, small.Contains(NewLocation(5, 5)))
+	assert.True(t, small.Contains(NewLocation(10, 0)))
+	assert.False(t, small.Contains(NewLocation(11, 5)))
+	assert.False(t, small.Contains(NewLocation(5, -1)))
+	// Intersects
+	assert.True(t, r.Intersects(small))
+	assert.True(t, small.Intersects(NewRegion(NewLocation(10, 10), NewLocation(20, 20))))
+	assert.False(t, small.Intersects(NewRegion(NewLocation(11, 0), NewLocation(20, 10))))
+	assert.False(t, small.Intersects(NewRegion(NewLocation(0, -20), NewLocation(10, -10))))
 }
 
 func TestEncode(t *testing.T) {
@@ -92,6 +103,14 @@ func TestValid(t *testing.T) {
 	}
 }
 
+func TestPrefixEnd(t *testing.T) {
+	assert.Equal(t, "9g3x", PrefixEnd("9g3w"))
+	assert.Equal(t, "9h", PrefixEnd("9gz"))
+	assert.Equal(t, "", PrefixEnd("zz"))
+	assert.Equal(t, "", PrefixEnd(""))
+	assert.True(t, "9g3wzzzz" < PrefixEnd("9g3w"))
+}
+
 func TestOutOfBounds(t *testing.T) {
 	// Min
 	assert.Equal(t, 0.0, fixOutOfBounds(-2.0, -1.0, 1.0))
@@ -0,0 +1,61 @@
+package geohash
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidGeoJSON is returned for GeoJSON geometries which are not valid polygons
+var ErrInvalidGeoJSON = errors.New("geohash: invalid GeoJSON polygon")
+
+// geometry is a GeoJSON geometry object, positions are [longitude, latitude]
+type geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// ParseGeoJSON decodes a GeoJSON Polygon or MultiPolygon geometry into polygons,
+// the first ring of each being its outer boundary and the rest its holes.
+// From: https://datatracker.ietf.org/doc/html/rfc7946#section-3.1.6
+func ParseGeoJSON(data []byte) ([]Polygon, error) {
+	var g geometry
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, err
+	}
+	var multi [][][][]float64
+	switch g.Type {
+	case "Polygon":
+		var rings [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &rings); err != nil {
+			return nil, err
+		}
+		multi = append(multi, rings)
+	case "MultiPolygon":
+		if err := json.Unmarshal(g.Coordinates, &multi); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidGeoJSON
+	}
+	polygons := make([]Polygon, 0, len(multi))
+	for _, rings := range multi {
+		if len(rings) == 0 {
+			return nil, ErrInvalidGeoJSON
+		}
+		locs := make([][]Location, len(rings))
+		for i, ring := range rings {
+			// Linear rings are closed with at least 4 positions
+			if len(ring) < 4 {
+				return nil, ErrInvalidGeoJSON
+			}
+			for _, position := range ring[:len(ring)-1] {
+				if len(position) < 2 {
+					return nil, ErrInvalidGeoJSON
+				}
+				locs[i] = append(locs[i], NewLocation(position[1], position[0]))
+			}
+		}
+		polygons = append(polygons, NewPolygon(locs[0], locs[1:]...))
+	}
+	return polygons, nil
+}
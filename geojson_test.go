@@ -0,0 +1,43 @@
+package geohash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGeoJSON(t *testing.T) {
+	polygons, err := ParseGeoJSON([]byte(`{"type": "Polygon", "coordinates": [
+		[[0, 0], [10, 0], [10, 10], [0, 10], [0, 0]],
+		[[4, 4], [6, 4], [6, 6], [4, 6], [4, 4]]
+	]}`))
+	if assert.NoError(t, err) && assert.Len(t, polygons, 1) {
+		p := polygons[0]
+		assert.Len(t, p.Outer(), 4, "Closing position is dropped")
+		assert.Len(t, p.Holes(), 1)
+		assert.Equal(t, NewLocation(0, 10), p.Outer()[1], "Positions are longitude, latitude")
+		assert.True(t, p.Contains(NewLocation(2, 2)))
+		assert.False(t, p.Contains(NewLocation(5, 5)))
+	}
+	polygons, err = ParseGeoJSON([]byte(`{"type": "MultiPolygon", "coordinates": [
+		[[[0, 0], [1, 0], [1, 1], [0, 0]]],
+		[[[5, 5], [6, 5], [6, 6], [5, 5]]]
+	]}`))
+	assert.NoError(t, err)
+	assert.Len(t, polygons, 2)
+	// Invalid
+	for _, v := range []string{
+		`{"type": "Point", "coordinates": [0, 0]}`,
+		`{"type": "Polygon", "coordinates": []}`,
+		`{"type": "Polygon", "coordinates": [[[0, 0], [1, 1], [0, 0]]]}`,
+		`{"type": "Polygon", "coordinates": [[[0], [1], [2], [0]]]}`,
+		`{"type": "MultiPolygon", "coordinates": [[]]}`,
+	} {
+		_, err := ParseGeoJSON([]byte(v))
+		assert.ErrorIs(t, err, ErrInvalidGeoJSON, v)
+	}
+	_, err = ParseGeoJSON([]byte(`{"type": "Polygon", "coordinates": "nope"}`))
+	assert.Error(t, err)
+	_, err = ParseGeoJSON([]byte(`not json`))
+	assert.Error(t, err)
+}
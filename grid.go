@@ -0,0 +1,90 @@
+package geohash
+
+// Order is the order in which Grid visits the cells
+type Order int
+
+const (
+	// RowMajor visits the cells row by row from South to North, each row from West to East
+	RowMajor Order = iota
+	// ZOrder visits the cells in geohash (lexicographic) order, following the Z-order curve
+	ZOrder
+)
+
+// Grid iterates every cell in the rectangle spanned by the South-West and North-East geohashes
+// (both included) in the given order. When the North-East cell lies west of the South-West one
+// the rectangle crosses the antimeridian. Geohashes of different length are taken at the
+// precision of the shorter one.
+func Grid(sw, ne string, order Order) Seq[string] {
+	precision := min(len(sw), len(ne))
+	sw, ne = sw[:precision], ne[:precision]
+	lonBits, _ := cellBits(precision)
+	columns := uint64(1) << lonBits
+	x0, y0 := cellIndex(sw)
+	x1, y1 := cellIndex(ne)
+	width := (x1 - x0 + columns) % columns
+	if order == ZOrder {
+		return func(yield func(string) bool) {
+			if precision > 0 && y0 <= y1 {
+				zorder("", precision, x0, width, y0, y1, yield)
+			}
+		}
+	}
+	return func(yield func(string) bool) {
+		if precision == 0 {
+			return
+		}
+		for y := y0; y <= y1; y++ {
+			for dx := uint64(0); dx <= width; dx++ {
+				if !yield(cellHash((x0+dx)%columns, y, precision)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Move returns the geohash dx columns East (negative West) and dy rows North (negative South)
+// of the given one, wrapping around the antimeridian. It is empty when moving past a pole.
+func Move(geohash string, dx, dy int) string {
+	precision := len(geohash)
+	lonBits, latBits := cellBits(precision)
+	columns, rows := int64(1)<<lonBits, int64(1)<<latBits
+	x, y := cellIndex(geohash)
+	row := int64(y) + int64(dy)
+	if row < 0 || row >= rows {
+		return ""
+	}
+	column := ((int64(x)+int64(dx))%columns + columns) % columns
+	return cellHash(uint64(column), uint64(row), precision)
+}
+
+// zorder descends from the prefix into the children intersecting the rectangle of columns
+// [x0, x0+width] (modulo the number of columns) and rows [y0, y1] at the given precision,
+// yielding the cells in geohash order. Returns false when the iteration was stopped.
+func zorder(prefix string, precision int, x0, width, y0, y1 uint64, yield func(string) bool) bool {
+	if len(prefix) == precision {
+		return yield(prefix)
+	}
+	lonBits, latBits := cellBits(precision)
+	columns := uint64(1) << lonBits
+	for _, char := range base32 {
+		child := prefix + string(char)
+		// Span of the child in cells of the target precision
+		childLon, childLat := cellBits(len(child))
+		cx, cy := cellIndex(child)
+		xShift, yShift := lonBits-childLon, latBits-childLat
+		minX, maxX := cx<<xShift, (cx+1)<<xShift-1
+		minY, maxY := cy<<yShift, (cy+1)<<yShift-1
+		if maxY < y0 || minY > y1 {
+			continue
+		}
+		// Offsets of the child's columns from x0, it intersects if any is within width
+		if start := (minX - x0 + columns) % columns; start > width && start+(maxX-minX) < columns {
+			continue
+		}
+		if !zorder(child, precision, x0, width, y0, y1, yield) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package geohash
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// collect gathers all the values of an iterator
+func collect[T any](seq Seq[T]) []T {
+	var values []T
+	seq(func(v T) bool {
+		values = append(values, v)
+		return true
+	})
+	return values
+}
+
+func TestGridRowMajor(t *testing.T) {
+	// Whole world at precision 1, row by row from south to north
+	assert.Equal(t, []string{
+		"0", "1", "4", "5", "h", "j", "n", "p",
+		"2", "3", "6", "7", "k", "m", "q", "r",
+		"8", "9", "d", "e", "s", "t", "w", "x",
+		"b", "c", "f", "g", "u", "v", "y", "z",
+	}, collect(Grid("0", "z", RowMajor)))
+	// Single cell
+	assert.Equal(t, []string{"9q"}, collect(Grid("9q", "9q", RowMajor)))
+	// Across the antimeridian
+	assert.Equal(t, []string{"p", "0", "r", "2"}, collect(Grid("p", "2", RowMajor)))
+	// Truncated to the shorter precision
+	assert.Equal(t, []string{"9", "d"}, collect(Grid("9q", "d", RowMajor)))
+	// North-East below South-West and empty geohashes
+	assert.Empty(t, collect(Grid("9", "2", RowMajor)))
+	assert.Empty(t, collect(Grid("", "", RowMajor)))
+}
+
+func TestGridZOrder(t *testing.T) {
+	// Whole world is every cell in geohash order
+	assert.Equal(t, []string(nil), collect(Grid("", "", ZOrder)))
+	world := collect(Grid("00", "zz", ZOrder))
+	assert.Len(t, world, 1024)
+	assert.True(t, sort.StringsAreSorted(world))
+	// Same cells as row-major, sorted
+	for _, box := range [][2]string{{"9g3w8", "9g3y2"}, {"pb", "25"}, {"9q", "9q"}, {"3e4m", "3e4t"}} {
+		rows := collect(Grid(box[0], box[1], RowMajor))
+		z := collect(Grid(box[0], box[1], ZOrder))
+		assert.True(t, sort.StringsAreSorted(z))
+		sort.Strings(rows)
+		assert.Equal(t, rows, z)
+	}
+}
+
+func TestGridStop(t *testing.T) {
+	for _, order := range []Order{RowMajor, ZOrder} {
+		var cells []string
+		Grid("0", "z", order)(func(cell string) bool {
+			cells = append(cells, cell)
+			return len(cells) < 3
+		})
+		assert.Len(t, cells, 3)
+	}
+}
+
+func TestMove(t *testing.T) {
+	for k, v := range Neighbours("9g3w8") {
+		offsets := map[string][2]int{
+			"n": {0, 1}, "s": {0, -1}, "e": {1, 0}, "w": {-1, 0},
+			"ne": {1, 1}, "se": {1, -1}, "sw": {-1, -1}, "nw": {-1, 1},
+		}[k]
+		assert.Equal(t, v, Move("9g3w8", offsets[0], offsets[1]), k)
+	}
+	assert.Equal(t, "9g3w8", Move("9g3w8", 0, 0))
+	// Round trip with Steps
+	far := Move("9g3w8", 17, -5)
+	dx, dy := Steps("9g3w8", far)
+	assert.Equal(t, 17, dx)
+	assert.Equal(t, -5, dy)
+	// Wraps around the antimeridian, not the poles
+	assert.Equal(t, "0", Move("p", 1, 0))
+	assert.Equal(t, "p", Move("0", -1, 0))
+	assert.Equal(t, "9", Move("9", 8, 0))
+	assert.Equal(t, "", Move("z", 0, 1))
+	assert.Equal(t, "", Move("0", 0, -1))
+}
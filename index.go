@@ -0,0 +1,189 @@
+package geohash
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// indexPrecision is the depth of the trie, every item is stored at a leaf of this precision
+const indexPrecision = 12
+
+// Item is a value stored at a given location
+type Item[T any] struct {
+	Location Location
+	Value    T
+}
+
+// Index is an in-memory spatial index of values by location, backed by a trie
+// keyed on the base32 characters of their geohashes. It is safe for concurrent
+// readers with a single writer.
+type Index[T comparable] struct {
+	mu   sync.RWMutex
+	root *trieNode[T]
+	size int
+}
+
+// trieNode is a geohash cell in the trie, only leaves hold items
+type trieNode[T comparable] struct {
+	children [32]*trieNode[T]
+	count    int // non nil children
+	items    []Item[T]
+}
+
+// NewIndex creates a new empty Index
+func NewIndex[T comparable]() *Index[T] {
+	return &Index[T]{root: &trieNode[T]{}}
+}
+
+// Len returns the number of items in the index
+func (idx *Index[T]) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.size
+}
+
+// Insert adds a value at the given location
+func (idx *Index[T]) Insert(loc Location, value T) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	node := idx.root
+	for _, char := range []byte(Encode(loc.lat, loc.lon, indexPrecision)) {
+		i := base32Index(char)
+		if node.children[i] == nil {
+			node.children[i] = &trieNode[T]{}
+			node.count++
+		}
+		node = node.children[i]
+	}
+	node.items = append(node.items, Item[T]{Location: loc, Value: value})
+	idx.size++
+}
+
+// Remove deletes a value previously inserted at the given location,
+// returns false if it was not found.
+func (idx *Index[T]) Remove(loc Location, value T) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	geohash := Encode(loc.lat, loc.lon, indexPrecision)
+	path := []*trieNode[T]{idx.root}
+	for _, char := range []byte(geohash) {
+		next := path[len(path)-1].children[base32Index(char)]
+		if next == nil {
+			return false
+		}
+		path = append(path, next)
+	}
+	leaf := path[len(path)-1]
+	for i, item := range leaf.items {
+		if item.Location == loc && item.Value == value {
+			leaf.items = append(leaf.items[:i], leaf.items[i+1:]...)
+			idx.size--
+			// Prune the branch back up while it is empty
+			for j := len(path) - 1; j > 0 && path[j].count == 0 && len(path[j].items) == 0; j-- {
+				path[j-1].children[base32Index(geohash[j-1])] = nil
+				path[j-1].count--
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// QueryRegion returns all the items located inside the region
+func (idx *Index[T]) QueryRegion(r Region) []Item[T] {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	var items []Item[T]
+	idx.root.walk("", func(geohash string, node *trieNode[T]) bool {
+		if !r.Intersects(Decode(geohash)) {
+			return false
+		}
+		for _, item := range node.items {
+			if r.Contains(item.Location) {
+				items = append(items, item)
+			}
+		}
+		return true
+	})
+	return items
+}
+
+// QueryRadius returns all the items within a distance in meters of the center
+func (idx *Index[T]) QueryRadius(center Location, meters float64) []Item[T] {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	var items []Item[T]
+	idx.root.walk("", func(geohash string, node *trieNode[T]) bool {
+		if regionDistance(Decode(geohash), center) > meters {
+			return false
+		}
+		for _, item := range node.items {
+			if Distance(center, item.Location) <= meters {
+				items = append(items, item)
+			}
+		}
+		return true
+	})
+	return items
+}
+
+// Nearest returns the k items closest to the given location, sorted by distance.
+// The trie is searched best-first, visiting cells in order of their distance.
+func (idx *Index[T]) Nearest(loc Location, k int) []Item[T] {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	var items []Item[T]
+	queue := &trieQueue[T]{{node: idx.root}}
+	for queue.Len() > 0 && len(items) < k {
+		next := heap.Pop(queue).(trieEntry[T])
+		if next.node == nil {
+			items = append(items, next.item)
+			continue
+		}
+		for _, item := range next.node.items {
+			heap.Push(queue, trieEntry[T]{distance: Distance(loc, item.Location), item: item})
+		}
+		for i, child := range next.node.children {
+			if child != nil {
+				geohash := next.geohash + string(base32[i])
+				heap.Push(queue, trieEntry[T]{distance: regionDistance(Decode(geohash), loc), geohash: geohash, node: child})
+			}
+		}
+	}
+	return items
+}
+
+// walk visits the node and its descendants depth-first in geohash order,
+// children are skipped when visit returns false.
+func (node *trieNode[T]) walk(geohash string, visit func(string, *trieNode[T]) bool) {
+	if !visit(geohash, node) {
+		return
+	}
+	for i, child := range node.children {
+		if child != nil {
+			child.walk(geohash+string(base32[i]), visit)
+		}
+	}
+}
+
+// trieEntry is either a trie node (cell) or an item waiting to be visited by Nearest
+type trieEntry[T comparable] struct {
+	distance float64
+	geohash  string
+	node     *trieNode[T]
+	item     Item[T]
+}
+
+// trieQueue is a min-heap of trie entries by distance, implements heap.Interface
+type trieQueue[T comparable] []trieEntry[T]
+
+func (q trieQueue[T]) Len() int           { return len(q) }
+func (q trieQueue[T]) Less(i, j int) bool { return q[i].distance < q[j].distance }
+func (q trieQueue[T]) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *trieQueue[T]) Push(x any)        { *q = append(*q, x.(trieEntry[T])) }
+func (q *trieQueue[T]) Pop() any {
+	old := *q
+	entry := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return entry
+}
@@ -0,0 +1,109 @@
+package geohash
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexInsertRemove(t *testing.T) {
+	idx := NewIndex[string]()
+	for _, v := range geohashTests {
+		idx.Insert(NewLocation(v.latitude, v.longitude), v.geohash)
+	}
+	assert.Equal(t, len(geohashTests), idx.Len())
+	// Same location, different values
+	loc := NewLocation(geohashTests[0].latitude, geohashTests[0].longitude)
+	idx.Insert(loc, "duplicate")
+	assert.Equal(t, len(geohashTests)+1, idx.Len())
+	// Remove
+	assert.True(t, idx.Remove(loc, "duplicate"))
+	assert.False(t, idx.Remove(loc, "duplicate"), "Already removed")
+	assert.False(t, idx.Remove(NewLocation(0, 0), "missing"))
+	for _, v := range geohashTests {
+		assert.True(t, idx.Remove(NewLocation(v.latitude, v.longitude), v.geohash))
+	}
+	assert.Equal(t, 0, idx.Len())
+	assert.Equal(t, 0, idx.root.count, "Empty branches should be pruned")
+}
+
+func TestIndexQueryRegion(t *testing.T) {
+	idx := NewIndex[string]()
+	for _, v := range geohashTests {
+		idx.Insert(NewLocation(v.latitude, v.longitude), v.geohash)
+	}
+	// Northern hemisphere, Europe
+	items := idx.QueryRegion(NewRegion(NewLocation(30, 0), NewLocation(60, 40)))
+	var values []string
+	for _, item := range items {
+		values = append(values, item.Value)
+	}
+	assert.ElementsMatch(t, []string{"sr2y7kh9bbfk", "ucfv0j9vp0xz"}, values)
+	// Whole world
+	assert.Len(t, idx.QueryRegion(NewRegion(NewLocation(-90, -180), NewLocation(90, 180))), len(geohashTests))
+	// Nothing
+	assert.Empty(t, idx.QueryRegion(NewRegion(NewLocation(-10, -10), NewLocation(10, 10))))
+}
+
+func TestIndexQueryRadiusAndNearest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	idx := NewIndex[int]()
+	var locations []Location
+	for i := 0; i < 1000; i++ {
+		loc := NewLocation(rng.Float64()*2, rng.Float64()*2)
+		locations = append(locations, loc)
+		idx.Insert(loc, i)
+	}
+	center := NewLocation(1, 1)
+	// Radius against brute force
+	var expected []int
+	for i, loc := range locations {
+		if Distance(center, loc) <= 50000 {
+			expected = append(expected, i)
+		}
+	}
+	var found []int
+	for _, item := range idx.QueryRadius(center, 50000) {
+		found = append(found, item.Value)
+	}
+	assert.ElementsMatch(t, expected, found)
+	// Nearest against brute force
+	sort.Slice(locations, func(i, j int) bool {
+		return Distance(center, locations[i]) < Distance(center, locations[j])
+	})
+	nearest := idx.Nearest(center, 10)
+	if assert.Len(t, nearest, 10) {
+		for i, item := range nearest {
+			assert.Equal(t, locations[i], item.Location)
+		}
+	}
+	assert.Len(t, idx.Nearest(center, 2000), 1000)
+	assert.Empty(t, NewIndex[int]().Nearest(center, 5))
+}
+
+func TestIndexConcurrency(t *testing.T) {
+	idx := NewIndex[int]()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			idx.Insert(NewLocation(float64(i%90), float64(i%180)), i)
+		}
+	}()
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				idx.QueryRadius(NewLocation(45, 90), 1000000)
+				idx.Nearest(NewLocation(0, 0), 3)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 500, idx.Len())
+}
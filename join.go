@@ -0,0 +1,94 @@
+package geohash
+
+import "math"
+
+// Pair is a match of a Join, with the distance in meters between both items
+type Pair[L, R any] struct {
+	Left     Item[L]
+	Right    Item[R]
+	Distance float64
+}
+
+// Join matches every left item with the right items within maxDistance meters of it, calling
+// yield with each pair until it returns false. Only left is streamed: right is not an iterator
+// but an indexed store answering prefix lookups, such as a geohash keyed database. The left
+// items are grouped by the geohash cell containing them, with a precision derived from the
+// distance, and for each group right is looked up in that cell and its neighbours (the cells
+// a circle of maxDistance around any of its items covers). Only a group and the right items
+// around it are held in memory, so neither side has to fit in it.
+//
+// Left items should come in geohash order, as scanned from a geohash keyed store, so each
+// group holds a whole cell and the right cells shared with the previous group are reused.
+// Unsorted items are still matched, with more lookups. Errors from right stop the join and
+// are returned.
+func Join[L, R any](left Seq[Item[L]], right Lookup[R], maxDistance float64, yield func(Pair[L, R]) bool) error {
+	precision := distancePrecision(maxDistance)
+	var (
+		group   []Item[L]
+		cell    string
+		fetched map[string][]Item[R] // right items by cell, around the previous group
+		err     error
+	)
+	// match compares the group with the right items around it, false to stop the join
+	match := func() bool {
+		covers := make([][]string, len(group))
+		cells := make(map[string][]Item[R])
+		for i, l := range group {
+			covers[i] = CoverCircle(l.Location, maxDistance, precision)
+			for _, c := range covers[i] {
+				if _, ok := cells[c]; ok {
+					continue
+				}
+				items, ok := fetched[c]
+				if !ok {
+					if items, err = right(c); err != nil {
+						return false
+					}
+				}
+				cells[c] = items
+			}
+		}
+		fetched = cells
+		for i, l := range group {
+			for _, c := range covers[i] {
+				for _, r := range cells[c] {
+					if d := Distance(l.Location, r.Location); d <= maxDistance {
+						if !yield(Pair[L, R]{Left: l, Right: r, Distance: d}) {
+							return false
+						}
+					}
+				}
+			}
+		}
+		return true
+	}
+	more := true
+	left(func(l Item[L]) bool {
+		geohash := Encode(l.Location.lat, l.Location.lon, precision)
+		if len(group) > 0 && geohash != cell {
+			if more = match(); !more {
+				return false
+			}
+			group = group[:0]
+		}
+		group, cell = append(group, l), geohash
+		return true
+	})
+	if more && len(group) > 0 {
+		match()
+	}
+	return err
+}
+
+// distancePrecision returns the finest precision whose cells are at least
+// the given size in meters (at the equator), so a circle of that radius
+// covers only a few of them.
+func distancePrecision(meters float64) int {
+	for precision := indexPrecision; precision > 1; precision-- {
+		height, width := cellSize(precision)
+		if radians(math.Min(height, width))*earthRadius >= meters {
+			return precision
+		}
+	}
+	return 1
+}
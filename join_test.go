@@ -0,0 +1,108 @@
+package geohash
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sliceLookup looks up the items of a slice under a geohash prefix, counting the calls
+func sliceLookup[T any](items []Item[T], calls *int) Lookup[T] {
+	return func(prefix string) ([]Item[T], error) {
+		*calls++
+		var found []Item[T]
+		for _, item := range items {
+			if Encode(item.Location.lat, item.Location.lon, len(prefix)) == prefix {
+				found = append(found, item)
+			}
+		}
+		return found, nil
+	}
+}
+
+func TestJoin(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	region := NewRegion(NewLocation(19.3, -99.3), NewLocation(19.6, -99.0))
+	var orders, stores []Item[int]
+	for i := 0; i < 300; i++ {
+		orders = append(orders, Item[int]{RandomIn(region, rng), i})
+	}
+	for i := 0; i < 50; i++ {
+		stores = append(stores, Item[int]{RandomIn(region, rng), i})
+	}
+	// Streamed in geohash order
+	sort.Slice(orders, func(i, j int) bool {
+		return Encode(orders[i].Location.lat, orders[i].Location.lon, 12) < Encode(orders[j].Location.lat, orders[j].Location.lon, 12)
+	})
+	// Against brute force
+	expected := make(map[[2]int]float64)
+	for _, o := range orders {
+		for _, s := range stores {
+			if d := Distance(o.Location, s.Location); d <= 2000 {
+				expected[[2]int{o.Value, s.Value}] = d
+			}
+		}
+	}
+	found := make(map[[2]int]float64)
+	calls := 0
+	err := Join(Slice(orders), sliceLookup(stores, &calls), 2000, func(p Pair[int, int]) bool {
+		key := [2]int{p.Left.Value, p.Right.Value}
+		_, duplicate := found[key]
+		assert.False(t, duplicate)
+		found[key] = p.Distance
+		return true
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, found)
+	assert.Equal(t, expected, found)
+	// Each cell is looked up once per group of left items around it, not once per item
+	cells := make(map[string]bool)
+	for _, o := range orders {
+		for _, cell := range CoverCircle(o.Location, 2000, distancePrecision(2000)) {
+			cells[cell] = true
+		}
+	}
+	assert.Less(t, calls, 3*len(cells))
+	// Unsorted left items are matched all the same
+	rng.Shuffle(len(orders), func(i, j int) { orders[i], orders[j] = orders[j], orders[i] })
+	unsorted := make(map[[2]int]float64)
+	assert.NoError(t, Join(Slice(orders), sliceLookup(stores, &calls), 2000, func(p Pair[int, int]) bool {
+		unsorted[[2]int{p.Left.Value, p.Right.Value}] = p.Distance
+		return true
+	}))
+	assert.Equal(t, expected, unsorted)
+	// Stops when yield returns false
+	count := 0
+	assert.NoError(t, Join(Slice(orders), sliceLookup(stores, &calls), 2000, func(p Pair[int, int]) bool {
+		count++
+		return false
+	}))
+	assert.Equal(t, 1, count)
+	// Nothing on one side
+	assert.NoError(t, Join(Slice(orders), sliceLookup[int](nil, &calls), 2000, func(p Pair[int, int]) bool {
+		t.Fail()
+		return true
+	}))
+	// Lookup errors stop the join
+	failed := errors.New("disk on fire")
+	err = Join(Slice(orders), func(prefix string) ([]Item[int], error) { return nil, failed }, 2000, func(p Pair[int, int]) bool {
+		t.Fail()
+		return true
+	})
+	assert.ErrorIs(t, err, failed)
+}
+
+func TestDistancePrecision(t *testing.T) {
+	assert.Equal(t, 1, distancePrecision(10000000))
+	assert.Equal(t, 5, distancePrecision(2000))
+	assert.Equal(t, 7, distancePrecision(100))
+	assert.Equal(t, 12, distancePrecision(0))
+	for _, meters := range []float64{1, 50, 1000, 30000} {
+		height, width := cellSize(distancePrecision(meters))
+		assert.GreaterOrEqual(t, radians(height)*earthRadius, meters)
+		assert.GreaterOrEqual(t, radians(width)*earthRadius, meters)
+	}
+}
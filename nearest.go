@@ -0,0 +1,98 @@
+package geohash
+
+import (
+	"math"
+	"sort"
+)
+
+// Lookup retrieves every item stored under a geohash prefix,
+// the empty prefix being the whole world.
+type Lookup[T any] func(prefix string) ([]Item[T], error)
+
+// Nearest finds the k items closest to loc, sorted by distance, using lookup to retrieve them.
+// It searches the cell with the given precision containing loc and its ring of 8 neighbours,
+// expanding to the ring of the parent cell until the k-th candidate is provably closer
+// than anything outside the searched area. The precision should be about the size of
+// the area expected to hold k items, the finer it is the less data is fetched when dense.
+func Nearest[T any](loc Location, k int, precision int, lookup Lookup[T]) ([]Item[T], error) {
+	return NearestWithin(loc, k, math.Inf(1), precision, lookup)
+}
+
+// NearestWithin is like Nearest but only finds items up to maxMeters away from loc, and stops
+// expanding once the searched area covers that distance, returning fewer than k if so.
+func NearestWithin[T any](loc Location, k int, maxMeters float64, precision int, lookup Lookup[T]) ([]Item[T], error) {
+	if k <= 0 {
+		return nil, nil
+	}
+	for ; precision >= 0; precision-- {
+		var candidates []Item[T]
+		for _, prefix := range ringCells(Encode(loc.lat, loc.lon, precision)) {
+			items, err := lookup(prefix)
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				if Distance(loc, item.Location) <= maxMeters {
+					candidates = append(candidates, item)
+				}
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return Distance(loc, candidates[i].Location) < Distance(loc, candidates[j].Location)
+		})
+		radius := math.Inf(1)
+		if precision > 0 {
+			radius = ringRadius(loc, precision)
+		}
+		if len(candidates) >= k && Distance(loc, candidates[k-1].Location) <= radius {
+			return candidates[:k], nil
+		}
+		// Nothing closer than maxMeters is left outside the searched area
+		if radius >= maxMeters {
+			return candidates, nil
+		}
+	}
+	return nil, nil
+}
+
+// ringCells returns the cell and its (up to) 8 neighbours without duplicates,
+// the empty geohash (whole world) has no neighbours.
+func ringCells(geohash string) []string {
+	if geohash == "" {
+		return []string{""}
+	}
+	seen := make(map[string]bool)
+	var cells []string
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if cell := Move(geohash, dx, dy); cell != "" && !seen[cell] {
+				seen[cell] = true
+				cells = append(cells, cell)
+			}
+		}
+	}
+	return cells
+}
+
+// ringRadius calculates a lower bound in meters of the distance from loc to any
+// point outside the ring of cells (with the given precision) around it.
+func ringRadius(loc Location, precision int) float64 {
+	height, width := cellSize(precision)
+	cell := Decode(Encode(loc.lat, loc.lon, precision))
+	radius := math.Inf(1)
+	// Parallels, unless the ring already reaches the pole
+	if south := cell.min.lat - height; south > -90 {
+		radius = math.Min(radius, radians(loc.lat-south)*earthRadius)
+	}
+	if north := cell.max.lat + height; north < 90 {
+		radius = math.Min(radius, radians(north-loc.lat)*earthRadius)
+	}
+	// Meridians, the distance to a whole great circle is a lower bound for its segment
+	for _, dLon := range []float64{loc.lon - (cell.min.lon - width), (cell.max.lon + width) - loc.lon} {
+		if dLon < 90 {
+			d := math.Asin(math.Cos(radians(loc.lat)) * math.Sin(radians(dLon)))
+			radius = math.Min(radius, d*earthRadius)
+		}
+	}
+	return radius
+}
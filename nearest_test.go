@@ -0,0 +1,131 @@
+package geohash
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// prefixLookup is a Lookup over a slice of items, counting the prefixes requested
+func prefixLookup(items []Item[int], calls *int) Lookup[int] {
+	return func(prefix string) ([]Item[int], error) {
+		*calls++
+		var found []Item[int]
+		for _, item := range items {
+			if strings.HasPrefix(Encode(item.Location.Latitude(), item.Location.Longitude(), 12), prefix) {
+				found = append(found, item)
+			}
+		}
+		return found, nil
+	}
+}
+
+func TestNearest(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	var items []Item[int]
+	for i := 0; i < 500; i++ {
+		items = append(items, Item[int]{NewLocation(rng.Float64()*170-85, rng.Float64()*360-180), i})
+	}
+	for _, v := range geohashTests {
+		loc := NewLocation(v.latitude, v.longitude)
+		sorted := append([]Item[int](nil), items...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return Distance(loc, sorted[i].Location) < Distance(loc, sorted[j].Location)
+		})
+		for _, precision := range []int{1, 3, 6} {
+			calls := 0
+			nearest, err := Nearest(loc, 5, precision, prefixLookup(items, &calls))
+			if assert.NoError(t, err) {
+				assert.Equal(t, sorted[:5], nearest)
+			}
+		}
+	}
+	// Fewer items than requested
+	calls := 0
+	nearest, err := Nearest(NewLocation(0, 0), 1000, 4, prefixLookup(items, &calls))
+	assert.NoError(t, err)
+	assert.Len(t, nearest, 500)
+	nearest, err = Nearest(NewLocation(0, 0), 0, 4, prefixLookup(items, &calls))
+	assert.NoError(t, err)
+	assert.Empty(t, nearest)
+}
+
+func TestNearestDense(t *testing.T) {
+	// Dense data close by should stop the expansion early
+	var items []Item[int]
+	for i := 0; i < 100; i++ {
+		items = append(items, Item[int]{NewLocation(19.43+float64(i)*0.0001, -99.13), i})
+	}
+	calls := 0
+	nearest, err := Nearest(NewLocation(19.43, -99.13), 3, 6, prefixLookup(items, &calls))
+	assert.NoError(t, err)
+	assert.Equal(t, items[:3], nearest)
+	assert.LessOrEqual(t, calls, 18, "Should not expand past two rings")
+}
+
+func TestNearestWithin(t *testing.T) {
+	var items []Item[int]
+	for i := 0; i < 100; i++ {
+		items = append(items, Item[int]{NewLocation(19.43+float64(i)*0.01, -99.13), i})
+	}
+	loc := NewLocation(19.43, -99.13)
+	// About 1.1 km between items
+	calls := 0
+	nearest, err := NearestWithin(loc, 5, 2500, 6, prefixLookup(items, &calls))
+	assert.NoError(t, err)
+	assert.Equal(t, items[:3], nearest)
+	// Stops expanding once the cutoff is covered, without reaching the whole world
+	calls = 0
+	nearest, err = NearestWithin(NewLocation(0, 0), 5, 1000, 6, prefixLookup(items, &calls))
+	assert.NoError(t, err)
+	assert.Empty(t, nearest)
+	assert.Less(t, calls, 30)
+	// Enough items within the cutoff
+	nearest, err = NearestWithin(loc, 2, 1e6, 6, prefixLookup(items, &calls))
+	assert.NoError(t, err)
+	assert.Equal(t, items[:2], nearest)
+}
+
+func TestNearestLookupError(t *testing.T) {
+	fail := errors.New("lookup failed")
+	_, err := Nearest(NewLocation(0, 0), 1, 5, func(string) ([]Item[int], error) {
+		return nil, fail
+	})
+	assert.ErrorIs(t, err, fail)
+}
+
+func TestRingCells(t *testing.T) {
+	assert.Equal(t, []string{""}, ringCells(""))
+	assert.ElementsMatch(t, []string{"9", "8", "d", "2", "3", "6", "b", "c", "f"}, ringCells("9"))
+	// Same as the neighbours
+	cells := []string{"9g3w8"}
+	for _, v := range Neighbours("9g3w8") {
+		cells = append(cells, v)
+	}
+	assert.ElementsMatch(t, cells, ringCells("9g3w8"))
+	// At the pole there is no row above
+	assert.Len(t, ringCells("z"), 6)
+}
+
+func TestRingRadius(t *testing.T) {
+	loc := NewLocation(19.43, -99.13)
+	for precision := 1; precision <= 8; precision++ {
+		radius := ringRadius(loc, precision)
+		assert.Greater(t, radius, 0.0)
+		// Every location closer than the radius should lie inside the ring
+		ring := ringCells(Encode(loc.Latitude(), loc.Longitude(), precision))
+		span := degrees(radius/earthRadius) * 2
+		for i := -10; i <= 10; i++ {
+			for j := -10; j <= 10; j++ {
+				to := NewLocation(loc.Latitude()+span*float64(i)/10, loc.Longitude()+span*float64(j)/10)
+				if Distance(loc, to) < radius*0.999 {
+					assert.Contains(t, ring, Encode(to.Latitude(), to.Longitude(), precision))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package geohash
+
+import "math"
+
+// Polygon is an area bounded by an outer ring of locations, optionally with holes.
+// Rings are closed implicitly (the last location connects to the first one) and
+// their edges are straight lines in latitude/longitude, polygons must not cross
+// the antimeridian.
+type Polygon struct {
+	rings  [][]Location
+	bounds Region
+}
+
+// NewPolygon creates a new polygon with the given outer ring and holes
+func NewPolygon(outer []Location, holes ...[]Location) Polygon {
+	p := Polygon{rings: append([][]Location{outer}, holes...)}
+	if len(outer) > 0 {
+		minLat, minLon := outer[0].lat, outer[0].lon
+		maxLat, maxLon := minLat, minLon
+		for _, loc := range outer[1:] {
+			minLat, maxLat = math.Min(minLat, loc.lat), math.Max(maxLat, loc.lat)
+			minLon, maxLon = math.Min(minLon, loc.lon), math.Max(maxLon, loc.lon)
+		}
+		p.bounds = NewRegion(NewLocation(minLat, minLon), NewLocation(maxLat, maxLon))
+	}
+	return p
+}
+
+// Outer returns the outer ring of the polygon
+func (p Polygon) Outer() []Location {
+	return p.rings[0]
+}
+
+// Holes returns the inner rings of the polygon
+func (p Polygon) Holes() [][]Location {
+	return p.rings[1:]
+}
+
+// Bounds returns the bounding box of the polygon
+func (p Polygon) Bounds() Region {
+	return p.bounds
+}
+
+// Contains checks if the location lies inside the polygon and outside its holes,
+// by casting a ray East and counting the edges it crosses (even-odd rule).
+func (p Polygon) Contains(loc Location) bool {
+	if !p.bounds.Contains(loc) {
+		return false
+	}
+	inside := false
+	for _, ring := range p.rings {
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			a, b := ring[i], ring[j]
+			if (a.lat > loc.lat) != (b.lat > loc.lat) &&
+				loc.lon < (b.lon-a.lon)*(loc.lat-a.lat)/(b.lat-a.lat)+a.lon {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// CoverPolygon calculates the geohashes covering the polygon, split between the cells lying
+// completely inside it and the cells with the given precision crossed by its boundary.
+// Inside cells are as coarse as possible, so every cell is either one of them, contained
+// in one of them, one of the boundary cells or outside the polygon.
+func CoverPolygon(p Polygon, precision int) (inside, boundary []string) {
+	var edges [][2]Location
+	for _, ring := range p.rings {
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			edges = append(edges, [2]Location{ring[j], ring[i]})
+		}
+	}
+	var cover func(prefix string, edges [][2]Location)
+	cover = func(prefix string, edges [][2]Location) {
+		for _, char := range base32 {
+			geohash := prefix + string(char)
+			cell := Decode(geohash)
+			if !cell.Intersects(p.bounds) {
+				continue
+			}
+			var crossing [][2]Location
+			for _, edge := range edges {
+				if segmentIntersects(cell, edge[0], edge[1]) {
+					crossing = append(crossing, edge)
+				}
+			}
+			switch {
+			case len(crossing) == 0:
+				// Not crossed by the boundary, the whole cell is either inside or outside
+				if p.Contains(cell.Center()) {
+					inside = append(inside, geohash)
+				}
+			case len(geohash) < precision:
+				cover(geohash, crossing)
+			default:
+				boundary = append(boundary, geohash)
+			}
+		}
+	}
+	if precision > 0 && len(p.rings[0]) > 0 {
+		cover("", edges)
+	}
+	return inside, boundary
+}
+
+// segmentIntersects checks if the segment from a to b crosses or touches the region,
+// clipping it against each side of the box (Liang-Barsky).
+func segmentIntersects(r Region, a, b Location) bool {
+	t0, t1 := 0.0, 1.0
+	dLon, dLat := b.lon-a.lon, b.lat-a.lat
+	clip := func(p, q float64) bool {
+		if p == 0 {
+			return q >= 0
+		}
+		t := q / p
+		if p < 0 {
+			if t > t1 {
+				return false
+			}
+			t0 = math.Max(t0, t)
+		} else {
+			if t < t0 {
+				return false
+			}
+			t1 = math.Min(t1, t)
+		}
+		return true
+	}
+	return clip(-dLon, a.lon-r.min.lon) && clip(dLon, r.max.lon-a.lon) &&
+		clip(-dLat, a.lat-r.min.lat) && clip(dLat, r.max.lat-a.lat)
+}
@@ -0,0 +1,84 @@
+package geohash
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// square returns a closed ring for the box between min and max (lat, lon)
+func square(minLat, minLon, maxLat, maxLon float64) []Location {
+	return []Location{
+		NewLocation(minLat, minLon), NewLocation(maxLat, minLon),
+		NewLocation(maxLat, maxLon), NewLocation(minLat, maxLon),
+	}
+}
+
+func TestPolygon(t *testing.T) {
+	outer := square(0, 0, 10, 10)
+	hole := square(4, 4, 6, 6)
+	p := NewPolygon(outer, hole)
+	assert.Equal(t, outer, p.Outer())
+	assert.Equal(t, [][]Location{hole}, p.Holes())
+	assert.Equal(t, NewRegion(NewLocation(0, 0), NewLocation(10, 10)), p.Bounds())
+	// Contains
+	assert.True(t, p.Contains(NewLocation(2, 2)))
+	assert.True(t, p.Contains(NewLocation(8, 5)))
+	assert.False(t, p.Contains(NewLocation(5, 5)), "Inside the hole")
+	assert.False(t, p.Contains(NewLocation(11, 5)))
+	assert.False(t, p.Contains(NewLocation(5, -1)))
+	// Triangle
+	triangle := NewPolygon([]Location{NewLocation(0, 0), NewLocation(10, 0), NewLocation(0, 10)})
+	assert.True(t, triangle.Contains(NewLocation(2, 2)))
+	assert.False(t, triangle.Contains(NewLocation(6, 6)))
+	// Empty
+	assert.False(t, NewPolygon(nil).Contains(NewLocation(0, 0)))
+}
+
+func TestCoverPolygon(t *testing.T) {
+	p := NewPolygon(square(19.3, -99.3, 19.6, -99.0), square(19.4, -99.2, 19.5, -99.1))
+	inside, boundary := CoverPolygon(p, 5)
+	assert.NotEmpty(t, inside)
+	assert.NotEmpty(t, boundary)
+	for _, cell := range boundary {
+		assert.Len(t, cell, 5)
+	}
+	// Every random point inside the polygon is covered, never by an outside cell
+	rng := rand.New(rand.NewSource(3))
+	covered := func(geohash string) (in, border bool) {
+		for _, cell := range inside {
+			in = in || strings.HasPrefix(geohash, cell)
+		}
+		for _, cell := range boundary {
+			border = border || strings.HasPrefix(geohash, cell)
+		}
+		return in, border
+	}
+	for i := 0; i < 2000; i++ {
+		loc := NewLocation(19.2+rng.Float64()*0.5, -99.4+rng.Float64()*0.5)
+		in, border := covered(Encode(loc.Latitude(), loc.Longitude(), 12))
+		assert.False(t, in && border, "Inside and boundary cells are disjoint")
+		if in {
+			assert.True(t, p.Contains(loc))
+		}
+		if p.Contains(loc) {
+			assert.True(t, in || border)
+		}
+	}
+	// Nothing to cover
+	inside, boundary = CoverPolygon(NewPolygon(nil), 5)
+	assert.Empty(t, inside)
+	assert.Empty(t, boundary)
+}
+
+func TestSegmentIntersects(t *testing.T) {
+	r := NewRegion(NewLocation(0, 0), NewLocation(10, 10))
+	assert.True(t, segmentIntersects(r, NewLocation(5, 5), NewLocation(6, 6)), "Inside")
+	assert.True(t, segmentIntersects(r, NewLocation(-5, 5), NewLocation(15, 5)), "Through")
+	assert.True(t, segmentIntersects(r, NewLocation(-5, -5), NewLocation(5, 5)), "Into")
+	assert.True(t, segmentIntersects(r, NewLocation(10, -5), NewLocation(10, 15)), "Along the edge")
+	assert.False(t, segmentIntersects(r, NewLocation(11, -5), NewLocation(11, 15)), "Above")
+	assert.False(t, segmentIntersects(r, NewLocation(-5, 20), NewLocation(20, 11)), "Past the corner")
+}
@@ -0,0 +1,128 @@
+package geohash
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// ErrInvalidPolyline is returned when decoding a malformed encoded polyline
+var ErrInvalidPolyline = errors.New("geohash: invalid encoded polyline")
+
+// CoverCircle calculates the geohashes with the given precision that lie
+// (at least partially) within a radius in meters of the center location,
+// sorted from the closest to the farthest.
+func CoverCircle(center Location, meters float64, precision int) []string {
+	distances := make(map[string]float64)
+	var cells []string
+	for _, cell := range regionCells(circleRegion(center, meters), precision) {
+		if _, ok := distances[cell]; ok {
+			continue
+		}
+		if d := regionDistance(Decode(cell), center); d <= meters {
+			distances[cell] = d
+			cells = append(cells, cell)
+		}
+	}
+	sort.SliceStable(cells, func(i, j int) bool {
+		return distances[cells[i]] < distances[cells[j]]
+	})
+	return cells
+}
+
+// CoverPolyline calculates the geohashes with the given precision that a path passes through,
+// including every cell within bufferMeters of it. The cells around each segment are flooded
+// from the one holding its start, testing each once against the whole great-circle segment,
+// so the work grows with the number of cells covered. They are returned deduplicated, each
+// segment's sorted by their distance to its start.
+func CoverPolyline(path []Location, bufferMeters float64, precision int) []string {
+	if len(path) == 1 {
+		return CoverCircle(path[0], bufferMeters, precision)
+	}
+	seen := make(map[string]bool)
+	var cells []string
+	for i := 1; i < len(path); i++ {
+		a, b := path[i-1], path[i]
+		start := Encode(a.lat, a.lon, precision)
+		distances := map[string]float64{start: 0} // from a, for the cells within the buffer
+		visited := map[string]bool{start: true}
+		segment := []string{start}
+		for j := 0; j < len(segment); j++ {
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					next := Move(segment[j], dx, dy)
+					if next == "" || visited[next] {
+						continue
+					}
+					visited[next] = true
+					if r := Decode(next); segmentDistance(r, a, b) <= bufferMeters {
+						distances[next] = regionDistance(r, a)
+						segment = append(segment, next)
+					}
+				}
+			}
+		}
+		sort.SliceStable(segment, func(i, j int) bool {
+			return distances[segment[i]] < distances[segment[j]]
+		})
+		for _, cell := range segment {
+			if !seen[cell] {
+				seen[cell] = true
+				cells = append(cells, cell)
+			}
+		}
+	}
+	return cells
+}
+
+// DecodePolyline decodes a Google encoded polyline string into a path of locations.
+// From: https://developers.google.com/maps/documentation/utilities/polylinealgorithm
+func DecodePolyline(encoded string) ([]Location, error) {
+	var path []Location
+	lat, lon := 0, 0
+	for i := 0; i < len(encoded); {
+		var deltas [2]int
+		for j := range deltas {
+			result, shift := 0, 0
+			for {
+				if i >= len(encoded) {
+					return nil, ErrInvalidPolyline
+				}
+				b := int(encoded[i]) - 63
+				i++
+				if b < 0 || b > 63 {
+					return nil, ErrInvalidPolyline
+				}
+				result |= (b & 0x1f) << shift
+				shift += 5
+				if b < 0x20 {
+					break
+				}
+			}
+			if result&1 != 0 {
+				deltas[j] = ^(result >> 1)
+			} else {
+				deltas[j] = result >> 1
+			}
+		}
+		lat += deltas[0]
+		lon += deltas[1]
+		path = append(path, NewLocation(float64(lat)/1e5, float64(lon)/1e5))
+	}
+	return path, nil
+}
+
+// circleRegion calculates the bounding box of a circle with a radius in meters.
+// Circles reaching a pole or wider than the globe span every longitude.
+func circleRegion(center Location, meters float64) Region {
+	dLat := degrees(meters / earthRadius)
+	minLat, maxLat := center.lat-dLat, center.lat+dLat
+	if minLat <= -90 || maxLat >= 90 {
+		return NewRegion(NewLocation(math.Max(minLat, -90), -180), NewLocation(math.Min(maxLat, 90), 180))
+	}
+	dLon := dLat / math.Cos(radians(center.lat))
+	if dLon >= 180 {
+		return NewRegion(NewLocation(minLat, -180), NewLocation(maxLat, 180))
+	}
+	return NewRegion(NewLocation(minLat, center.lon-dLon), NewLocation(maxLat, center.lon+dLon))
+}
@@ -0,0 +1,103 @@
+package geohash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverCircle(t *testing.T) {
+	center := NewLocation(19.43265922422016, -99.13317967733457) // Mexico - CDMX Zócalo
+	// Zero radius is just the cell containing the center
+	assert.Equal(t, []string{"9g3w8"}, CoverCircle(center, 0, 5))
+	// Precision 0 is the whole world
+	assert.Equal(t, []string{""}, CoverCircle(center, 5000, 0))
+	cells := CoverCircle(center, 5000, 5)
+	assert.Equal(t, "9g3w8", cells[0], "Closest cell first")
+	for _, cell := range cells {
+		assert.LessOrEqual(t, regionDistance(Decode(cell), center), 5000.0)
+	}
+	for _, cell := range []string{"9g3w2", "9g3w9", "9g3qx"} {
+		assert.Contains(t, cells, cell)
+	}
+	// Reaching the pole covers every longitude
+	assert.Len(t, CoverCircle(NewLocation(89, 0), 200000, 1), 8)
+	// Across the antimeridian
+	cells = CoverCircle(NewLocation(0, 179.9), 50000, 2)
+	assert.ElementsMatch(t, []string{"rz", "xb", "2p", "80"}, cells)
+}
+
+func TestCoverPolyline(t *testing.T) {
+	path := []Location{NewLocation(2.8, 1), NewLocation(2.8, 30)}
+	cells := CoverPolyline(path, 0, 2)
+	assert.Equal(t, []string{"s0", "s2", "s8"}, cells)
+	// Single point is a circle
+	assert.Equal(t, CoverCircle(path[0], 1000, 5), CoverPolyline(path[:1], 1000, 5))
+	// Buffered cells are a superset, in order and without duplicates
+	buffered := CoverPolyline(path, 200000, 2)
+	assert.Subset(t, buffered, cells)
+	assert.Equal(t, []string{"s0", "eb", "s2", "s8"}, buffered)
+	seen := make(map[string]bool)
+	for _, cell := range buffered {
+		assert.False(t, seen[cell])
+		seen[cell] = true
+	}
+	// Empty path
+	assert.Empty(t, CoverPolyline(nil, 100, 5))
+}
+
+func TestCoverPolylineBuffer(t *testing.T) {
+	// Against circles around dense samples of every segment: all the cells within the
+	// buffer are covered, and only those (up to the gap between samples)
+	path := []Location{NewLocation(19.3, -99.3), NewLocation(19.6, -99.0), NewLocation(19.4, -98.8), NewLocation(19.4, -98.7)}
+	const buffer, samples = 1500, 500
+	cells := CoverPolyline(path, buffer, 6)
+	covered := make(map[string]bool)
+	for _, cell := range cells {
+		covered[cell] = true
+	}
+	closest := make(map[string]float64)
+	for i := 1; i < len(path); i++ {
+		for j := 0; j <= samples; j++ {
+			loc := interpolate(path[i-1], path[i], float64(j)/samples)
+			for _, cell := range CoverCircle(loc, buffer, 6) {
+				assert.True(t, covered[cell], cell)
+			}
+			for _, cell := range cells {
+				if d, ok := closest[cell]; !ok || regionDistance(Decode(cell), loc) < d {
+					closest[cell] = regionDistance(Decode(cell), loc)
+				}
+			}
+		}
+	}
+	for _, cell := range cells {
+		assert.LessOrEqual(t, closest[cell], buffer+50.0, cell)
+	}
+	// Without a buffer, the cells the segment passes through
+	path = []Location{NewLocation(19.43, -99.13), NewLocation(19.44, -99.12)}
+	cells = CoverPolyline(path, 0, 9)
+	assert.Len(t, cells, 467)
+	for j := 0; j <= samples; j++ {
+		loc := interpolate(path[0], path[1], float64(j)/samples)
+		assert.Contains(t, cells, Encode(loc.lat, loc.lon, 9))
+	}
+}
+
+func TestDecodePolyline(t *testing.T) {
+	path, err := DecodePolyline("_p~iF~ps|U_ulLnnqC_mqNvxq`@")
+	if assert.NoError(t, err) && assert.Len(t, path, 3) {
+		expected := []Location{NewLocation(38.5, -120.2), NewLocation(40.7, -120.95), NewLocation(43.252, -126.453)}
+		for i, loc := range expected {
+			assert.InDelta(t, loc.Latitude(), path[i].Latitude(), 1e-9)
+			assert.InDelta(t, loc.Longitude(), path[i].Longitude(), 1e-9)
+		}
+	}
+	path, err = DecodePolyline("")
+	assert.NoError(t, err)
+	assert.Empty(t, path)
+	// Truncated and out of range characters
+	for _, v := range []string{"_p~iF~ps|U_ulL", "_p~iF~ps|", "_p~iF ~ps|U"} {
+		_, err := DecodePolyline(v)
+		assert.ErrorIs(t, err, ErrInvalidPolyline)
+	}
+}
@@ -0,0 +1,45 @@
+package geohash
+
+import "math/rand"
+
+// Truncate reduces a geohash to at most the given precision, hiding the finer location.
+// Precisions below 0 truncate to the empty geohash, the whole world.
+func Truncate(geohash string, precision int) string {
+	if len(geohash) > precision {
+		return geohash[:max(precision, 0)]
+	}
+	return geohash
+}
+
+// Jitter moves the location to a random point inside its cell with the given precision,
+// so the published location is never farther than the cell's size from the real one
+// but does not reveal where in the cell it was.
+func Jitter(loc Location, precision int, rng *rand.Rand) Location {
+	return RandomInGeohash(Encode(loc.lat, loc.lon, precision), rng)
+}
+
+// KAnonymize generalizes each point to the finest geohash containing at least k of the points,
+// so each one is indistinguishable among k users. Points which cannot be generalized (fewer
+// than k in total) get an empty geohash and should not be published.
+func KAnonymize(points []Location, k int) []string {
+	hashes := make([]string, len(points))
+	counts := make(map[string]int)
+	for i, loc := range points {
+		hashes[i] = Encode(loc.lat, loc.lon, indexPrecision)
+		for j := 0; j <= indexPrecision; j++ {
+			counts[hashes[i][:j]]++
+		}
+	}
+	for i, geohash := range hashes {
+		for j := indexPrecision; j >= 0; j-- {
+			if counts[geohash[:j]] >= k {
+				hashes[i] = geohash[:j]
+				break
+			}
+		}
+		if counts[""] < k {
+			hashes[i] = ""
+		}
+	}
+	return hashes
+}
@@ -0,0 +1,68 @@
+package geohash
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "9g3w", Truncate("9g3w81t7mqpx", 4))
+	assert.Equal(t, "9g3", Truncate("9g3", 4))
+	assert.Equal(t, "", Truncate("9g3", 0))
+	assert.Equal(t, "", Truncate("9g3", -1))
+	assert.Equal(t, "", Truncate("", -1))
+}
+
+func TestJitter(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	loc := NewLocation(19.43265922422016, -99.13317967733457)
+	cell := Decode("9g3w8")
+	different := false
+	for i := 0; i < 100; i++ {
+		j := Jitter(loc, 5, rng)
+		assert.True(t, cell.Contains(j))
+		different = different || j != loc
+	}
+	assert.True(t, different)
+	// Deterministic for a given seed
+	a := Jitter(loc, 5, rand.New(rand.NewSource(1)))
+	b := Jitter(loc, 5, rand.New(rand.NewSource(1)))
+	assert.Equal(t, a, b)
+}
+
+func TestKAnonymize(t *testing.T) {
+	points := []Location{
+		NewLocation(19.4326, -99.1331),  // Zócalo
+		NewLocation(19.4327, -99.1332),  // Zócalo, next door
+		NewLocation(19.4340, -99.1410),  // Alameda
+		NewLocation(20.6738, -103.3440), // Guadalajara
+		NewLocation(41.9022, 12.4537),   // Vatican
+	}
+	hashes := KAnonymize(points, 1)
+	for i, loc := range points {
+		assert.Equal(t, Encode(loc.Latitude(), loc.Longitude(), 12), hashes[i])
+	}
+	hashes = KAnonymize(points, 2)
+	assert.Equal(t, hashes[0], hashes[1])
+	assert.Equal(t, CommonPrefix(Encode(19.4326, -99.1331, 12), Encode(19.4327, -99.1332, 12)), hashes[0])
+	assert.Equal(t, CommonPrefix(hashes[0], Encode(19.4340, -99.1410, 12)), hashes[2])
+	assert.Equal(t, "9", hashes[3])
+	assert.Equal(t, "", hashes[4])
+	// Every cell holds at least k points
+	for _, k := range []int{2, 3, 5} {
+		hashes = KAnonymize(points, k)
+		for _, h := range hashes {
+			count := 0
+			for _, loc := range points {
+				if Encode(loc.Latitude(), loc.Longitude(), 12)[:len(h)] == h {
+					count++
+				}
+			}
+			assert.GreaterOrEqual(t, count, k)
+		}
+	}
+	// Not enough points
+	assert.Equal(t, []string{"", "", "", "", ""}, KAnonymize(points, 6))
+}
@@ -0,0 +1,36 @@
+package geohash
+
+import (
+	"math"
+	"math/rand"
+)
+
+// maxRejections bounds the attempts of RandomInPolygon to sample a point inside the polygon
+const maxRejections = 10000
+
+// RandomIn returns a random location inside the region, uniformly distributed over the
+// surface of the sphere: latitudes closer to the poles are less likely, as there is less
+// area between their parallels. The same rng seed always yields the same locations.
+func RandomIn(r Region, rng *rand.Rand) Location {
+	sinMin, sinMax := math.Sin(radians(r.min.lat)), math.Sin(radians(r.max.lat))
+	lat := degrees(math.Asin(sinMin + rng.Float64()*(sinMax-sinMin)))
+	lon := r.min.lon + rng.Float64()*(r.max.lon-r.min.lon)
+	// Rounding at the edges must not leave the region
+	return NewLocation(math.Max(r.min.lat, math.Min(r.max.lat, lat)), lon)
+}
+
+// RandomInGeohash returns a random location inside the geohash's region, see RandomIn
+func RandomInGeohash(geohash string, rng *rand.Rand) Location {
+	return RandomIn(Decode(geohash), rng)
+}
+
+// RandomInPolygon returns a random location inside the polygon (outside its holes), sampling
+// its bounding box until one falls inside. Returns false for polygons with (almost) no area.
+func RandomInPolygon(p Polygon, rng *rand.Rand) (Location, bool) {
+	for i := 0; i < maxRejections; i++ {
+		if loc := RandomIn(p.bounds, rng); p.Contains(loc) {
+			return loc, true
+		}
+	}
+	return Location{}, false
+}
@@ -0,0 +1,55 @@
+package geohash
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomIn(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	world := NewRegion(NewLocation(-90, -180), NewLocation(90, 180))
+	// Area correct: the band within 30 degrees of the equator holds half of the sphere,
+	// while uniform degrees would put only a third of the points there
+	band := 0
+	for i := 0; i < 20000; i++ {
+		loc := RandomIn(world, rng)
+		assert.True(t, world.Contains(loc))
+		if loc.Latitude() > -30 && loc.Latitude() < 30 {
+			band++
+		}
+	}
+	assert.InDelta(t, 0.5, float64(band)/20000, 0.02)
+	// Small region
+	r := NewRegion(NewLocation(19.3, -99.3), NewLocation(19.6, -99.0))
+	for i := 0; i < 1000; i++ {
+		assert.True(t, r.Contains(RandomIn(r, rng)))
+	}
+	// Deterministic for a given seed
+	assert.Equal(t, RandomIn(r, rand.New(rand.NewSource(9))), RandomIn(r, rand.New(rand.NewSource(9))))
+}
+
+func TestRandomInGeohash(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	for _, v := range geohashTests {
+		for i := 1; i <= len(v.geohash); i++ {
+			loc := RandomInGeohash(v.geohash[:i], rng)
+			assert.Equal(t, v.geohash[:i], Encode(loc.Latitude(), loc.Longitude(), i))
+		}
+	}
+}
+
+func TestRandomInPolygon(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	p := NewPolygon(square(0, 0, 10, 10), square(2, 2, 8, 8))
+	for i := 0; i < 1000; i++ {
+		loc, ok := RandomInPolygon(p, rng)
+		if assert.True(t, ok) {
+			assert.True(t, p.Contains(loc))
+		}
+	}
+	// No area
+	_, ok := RandomInPolygon(NewPolygon([]Location{NewLocation(0, 0), NewLocation(1, 1)}), rng)
+	assert.False(t, ok)
+}
@@ -0,0 +1,70 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+)
+
+// glyphs is a 3x5 pixel font for the geohash characters, each row is 3 bits
+var glyphs = map[byte][5]uint8{
+	'0': {7, 5, 5, 5, 7}, '1': {2, 6, 2, 2, 7}, '2': {7, 1, 7, 4, 7}, '3': {7, 1, 7, 1, 7},
+	'4': {5, 5, 7, 1, 1}, '5': {7, 4, 7, 1, 7}, '6': {7, 4, 7, 5, 7}, '7': {7, 1, 1, 1, 1},
+	'8': {7, 5, 7, 5, 7}, '9': {7, 5, 7, 1, 7}, 'b': {4, 4, 7, 5, 7}, 'c': {0, 7, 4, 4, 7},
+	'd': {1, 1, 7, 5, 7}, 'e': {7, 5, 7, 4, 7}, 'f': {3, 4, 7, 4, 4}, 'g': {7, 5, 7, 1, 6},
+	'h': {4, 4, 7, 5, 5}, 'j': {1, 0, 1, 5, 7}, 'k': {4, 5, 6, 5, 5}, 'm': {0, 7, 7, 5, 5},
+	'n': {0, 6, 5, 5, 5}, 'p': {7, 5, 7, 4, 4}, 'q': {7, 5, 7, 1, 1}, 'r': {0, 7, 4, 4, 4},
+	's': {3, 4, 2, 1, 6}, 't': {2, 7, 2, 2, 3}, 'u': {0, 5, 5, 5, 7}, 'v': {0, 5, 5, 5, 2},
+	'w': {0, 5, 5, 7, 7}, 'x': {0, 5, 2, 5, 5}, 'y': {5, 5, 7, 1, 7}, 'z': {0, 7, 2, 4, 7},
+}
+
+// label writes the text centered in the rectangle, at the largest integer
+// scale of the font that fits with a pixel of padding, if any.
+func label(img draw.Image, r image.Rectangle, text string, c color.Color) {
+	text = strings.ToLower(text)
+	// Each glyph is 3 pixels wide plus 1 of spacing, 5 pixels high
+	width := 4*len(text) - 1
+	scale := min((r.Dx()-2)/width, (r.Dy()-2)/5)
+	if scale < 1 || len(text) == 0 {
+		return
+	}
+	x0 := r.Min.X + (r.Dx()-width*scale)/2
+	y0 := r.Min.Y + (r.Dy()-5*scale)/2
+	src := image.NewUniform(c)
+	for i := 0; i < len(text); i++ {
+		glyph := glyphs[text[i]]
+		for row, bits := range glyph {
+			for col := 0; col < 3; col++ {
+				if bits>>(2-col)&1 == 1 {
+					x, y := x0+(4*i+col)*scale, y0+row*scale
+					draw.Draw(img, image.Rect(x, y, x+scale, y+scale), src, image.Point{}, draw.Src)
+				}
+			}
+		}
+	}
+}
+
+// outline draws the border of the rectangle with the given thickness inwards
+func outline(img draw.Image, r image.Rectangle, c color.Color, thickness int) {
+	src := image.NewUniform(c)
+	for _, side := range []image.Rectangle{
+		image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+thickness),
+		image.Rect(r.Min.X, r.Max.Y-thickness, r.Max.X, r.Max.Y),
+		image.Rect(r.Min.X, r.Min.Y, r.Min.X+thickness, r.Max.Y),
+		image.Rect(r.Max.X-thickness, r.Min.Y, r.Max.X, r.Max.Y),
+	} {
+		draw.Draw(img, side.Intersect(r), src, image.Point{}, draw.Src)
+	}
+}
+
+// disc draws a filled circle centered in (cx, cy)
+func disc(img draw.Image, cx, cy, radius int, c color.Color) {
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if x*x+y*y <= radius*radius {
+				img.Set(cx+x, cy+y, c)
+			}
+		}
+	}
+}
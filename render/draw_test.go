@@ -0,0 +1,56 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlyphs(t *testing.T) {
+	for _, c := range []byte("0123456789bcdefghjkmnpqrstuvwxyz") {
+		glyph, ok := glyphs[c]
+		if assert.True(t, ok, string(c)) {
+			for _, row := range glyph {
+				assert.Less(t, row, uint8(8))
+			}
+		}
+	}
+	assert.Len(t, glyphs, 32)
+}
+
+// count returns the pixels of the given color
+func count(img *image.RGBA, c color.RGBA) int {
+	n := 0
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			if img.RGBAAt(x, y) == c {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestLabel(t *testing.T) {
+	black := color.RGBA{0, 0, 0, 255}
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	label(img, img.Bounds(), "1", black)
+	// "1" has 8 pixels set, scaled by 19 (fits 5 rows in 98 pixels)
+	assert.Equal(t, 8*19*19, count(img, black))
+	// Does not fit
+	img = image.NewRGBA(image.Rect(0, 0, 10, 10))
+	label(img, img.Bounds(), "9g3w", black)
+	assert.Equal(t, 0, count(img, black))
+}
+
+func TestOutlineAndDisc(t *testing.T) {
+	black := color.RGBA{0, 0, 0, 255}
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	outline(img, img.Bounds(), black, 1)
+	assert.Equal(t, 36, count(img, black))
+	img = image.NewRGBA(image.Rect(0, 0, 10, 10))
+	disc(img, 5, 5, 1, black)
+	assert.Equal(t, 5, count(img, black))
+}
@@ -0,0 +1,235 @@
+// Package render draws geohash cells, regions and points on a map projection,
+// as SVG or PNG images, to visualize coverages and explain how geohashes work.
+// Only the standard library is used, PNG labels are drawn with a tiny bitmap font.
+package render
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/phrozen/geohash"
+)
+
+// Projection maps locations on the sphere to the plane of the canvas
+type Projection int
+
+const (
+	// Equirectangular maps longitude and latitude linearly to x and y
+	Equirectangular Projection = iota
+	// WebMercator is the projection of web maps, it preserves angles but
+	// stretches areas towards the poles (cut at ±85.0511 degrees).
+	WebMercator
+)
+
+// maxMercator is the latitude at which Web Mercator is cut to make the world square
+const maxMercator = 85.05112877980659
+
+var (
+	background = color.NRGBA{255, 255, 255, 255}
+	cellFill   = color.NRGBA{59, 130, 246, 64}
+	cellStroke = color.NRGBA{30, 64, 175, 255}
+	regionLine = color.NRGBA{220, 38, 38, 255}
+	pointFill  = color.NRGBA{17, 24, 39, 255}
+)
+
+// Canvas collects geohashes, regions and points to draw
+type Canvas struct {
+	width, height int
+	projection    Projection
+	bounds        *geohash.Region
+	hashes        []string
+	regions       []geohash.Region
+	points        []geohash.Location
+}
+
+// New creates an empty canvas of the given size in pixels
+func New(width, height int, projection Projection) *Canvas {
+	return &Canvas{width: width, height: height, projection: projection}
+}
+
+// SetBounds sets the area shown by the canvas, by default it fits everything drawn on it
+func (c *Canvas) SetBounds(r geohash.Region) {
+	c.bounds = &r
+}
+
+// AddGeohashes draws the cells of the geohashes, labelled with their code when they fit
+func (c *Canvas) AddGeohashes(hashes ...string) {
+	c.hashes = append(c.hashes, hashes...)
+}
+
+// AddRegions draws the outline of the regions
+func (c *Canvas) AddRegions(regions ...geohash.Region) {
+	c.regions = append(c.regions, regions...)
+}
+
+// AddPoints draws the locations as dots
+func (c *Canvas) AddPoints(points ...geohash.Location) {
+	c.points = append(c.points, points...)
+}
+
+// SVG writes the canvas as an SVG image
+func (c *Canvas) SVG(w io.Writer) error {
+	v := c.viewport()
+	p := &printer{w: w}
+	p.printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", c.width, c.height, c.width, c.height)
+	p.printf(`<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hex(background))
+	p.printf(`<g fill="%s" fill-opacity="%.2f" stroke="%s">`+"\n", hex(cellFill), float64(cellFill.A)/255, hex(cellStroke))
+	for _, hash := range c.hashes {
+		r := v.rect(geohash.Decode(hash))
+		p.printf(`<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f"/>`+"\n", r.x0, r.y0, r.x1-r.x0, r.y1-r.y0)
+	}
+	p.printf("</g>\n")
+	p.printf(`<g fill="%s" font-family="monospace" text-anchor="middle" dominant-baseline="central">`+"\n", hex(cellStroke))
+	for _, hash := range c.hashes {
+		r := v.rect(geohash.Decode(hash))
+		// Monospace characters are about 0.6em wide
+		size := math.Min((r.x1-r.x0)*0.9/(0.6*float64(len(hash))), (r.y1-r.y0)*0.5)
+		if size >= 4 {
+			// Geohashes are not validated, escape them so they can't inject markup
+			p.printf(`<text x="%.2f" y="%.2f" font-size="%.2f">%s</text>`+"\n", (r.x0+r.x1)/2, (r.y0+r.y1)/2, size, html.EscapeString(hash))
+		}
+	}
+	p.printf("</g>\n")
+	p.printf(`<g fill="none" stroke="%s" stroke-width="2" stroke-dasharray="6 3">`+"\n", hex(regionLine))
+	for _, region := range c.regions {
+		r := v.rect(region)
+		p.printf(`<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f"/>`+"\n", r.x0, r.y0, r.x1-r.x0, r.y1-r.y0)
+	}
+	p.printf("</g>\n")
+	p.printf(`<g fill="%s">`+"\n", hex(pointFill))
+	for _, loc := range c.points {
+		x, y := v.point(loc)
+		p.printf(`<circle cx="%.2f" cy="%.2f" r="3"/>`+"\n", x, y)
+	}
+	p.printf("</g>\n</svg>\n")
+	return p.err
+}
+
+// PNG writes the canvas as a PNG image
+func (c *Canvas) PNG(w io.Writer) error {
+	return png.Encode(w, c.Image())
+}
+
+// Image draws the canvas into an RGBA image
+func (c *Canvas) Image() *image.RGBA {
+	v := c.viewport()
+	img := image.NewRGBA(image.Rect(0, 0, c.width, c.height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	for _, hash := range c.hashes {
+		r := v.rect(geohash.Decode(hash)).image()
+		draw.Draw(img, r, image.NewUniform(cellFill), image.Point{}, draw.Over)
+		outline(img, r, cellStroke, 1)
+		label(img, r, hash, cellStroke)
+	}
+	for _, region := range c.regions {
+		outline(img, v.rect(region).image(), regionLine, 2)
+	}
+	for _, loc := range c.points {
+		x, y := v.point(loc)
+		disc(img, int(math.Round(x)), int(math.Round(y)), 3, pointFill)
+	}
+	return img
+}
+
+// viewport returns the transformation from locations to pixels fitting the bounds in the canvas
+func (c *Canvas) viewport() viewport {
+	v := viewport{projection: c.projection}
+	bounds := c.fit()
+	x0, y0 := c.projection.project(bounds.Min())
+	x1, y1 := c.projection.project(bounds.Max())
+	// Same scale on both axes to keep the shapes, centered in the canvas
+	v.scale = math.Min(float64(c.width)/math.Max(x1-x0, 1e-9), float64(c.height)/math.Max(y1-y0, 1e-9))
+	v.dx = (float64(c.width) - (x1-x0)*v.scale) / 2
+	v.dy = (float64(c.height) - (y1-y0)*v.scale) / 2
+	v.x0, v.y1 = x0, y1
+	return v
+}
+
+// fit returns the bounds set, or the ones of everything drawn with a 5% margin
+func (c *Canvas) fit() geohash.Region {
+	if c.bounds != nil {
+		return *c.bounds
+	}
+	var locs []geohash.Location
+	for _, hash := range c.hashes {
+		r := geohash.Decode(hash)
+		locs = append(locs, r.Min(), r.Max())
+	}
+	for _, r := range c.regions {
+		locs = append(locs, r.Min(), r.Max())
+	}
+	locs = append(locs, c.points...)
+	if len(locs) == 0 {
+		return geohash.NewRegion(geohash.NewLocation(-90, -180), geohash.NewLocation(90, 180))
+	}
+	minLat, minLon := locs[0].Latitude(), locs[0].Longitude()
+	maxLat, maxLon := minLat, minLon
+	for _, loc := range locs[1:] {
+		minLat, maxLat = math.Min(minLat, loc.Latitude()), math.Max(maxLat, loc.Latitude())
+		minLon, maxLon = math.Min(minLon, loc.Longitude()), math.Max(maxLon, loc.Longitude())
+	}
+	margin := math.Max(math.Max(maxLat-minLat, maxLon-minLon)*0.05, 1e-4)
+	return geohash.NewRegion(
+		geohash.NewLocation(math.Max(minLat-margin, -90), math.Max(minLon-margin, -180)),
+		geohash.NewLocation(math.Min(maxLat+margin, 90), math.Min(maxLon+margin, 180)),
+	)
+}
+
+// project maps a location to the plane, x grows East and y grows North
+func (p Projection) project(loc geohash.Location) (x, y float64) {
+	if p == WebMercator {
+		lat := math.Max(-maxMercator, math.Min(maxMercator, loc.Latitude())) * math.Pi / 180
+		return loc.Longitude(), math.Log(math.Tan(math.Pi/4+lat/2)) * 180 / math.Pi
+	}
+	return loc.Longitude(), loc.Latitude()
+}
+
+// viewport transforms projected coordinates into pixels, y grows downwards
+type viewport struct {
+	projection    Projection
+	x0, y1        float64 // top-left corner in projected coordinates
+	scale, dx, dy float64
+}
+
+// rectangle is an area in pixels
+type rectangle struct {
+	x0, y0, x1, y1 float64
+}
+
+func (v viewport) point(loc geohash.Location) (x, y float64) {
+	px, py := v.projection.project(loc)
+	return v.dx + (px-v.x0)*v.scale, v.dy + (v.y1-py)*v.scale
+}
+
+// rect maps a region to pixels, both projections map boxes to boxes
+func (v viewport) rect(r geohash.Region) rectangle {
+	x0, y1 := v.point(r.Min())
+	x1, y0 := v.point(r.Max())
+	return rectangle{x0, y0, x1, y1}
+}
+
+func (r rectangle) image() image.Rectangle {
+	return image.Rect(int(math.Round(r.x0)), int(math.Round(r.y0)), int(math.Round(r.x1)), int(math.Round(r.y1)))
+}
+
+// printer writes formatted output keeping the first error
+type printer struct {
+	w   io.Writer
+	err error
+}
+
+func (p *printer) printf(format string, args ...any) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
+
+func hex(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
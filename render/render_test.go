@@ -0,0 +1,100 @@
+package render
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/phrozen/geohash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSVG(t *testing.T) {
+	c := New(800, 400, Equirectangular)
+	c.AddGeohashes("9g3w", "9g3x")
+	c.AddRegions(geohash.NewRegion(geohash.NewLocation(19.3, -99.3), geohash.NewLocation(19.6, -99.0)))
+	c.AddPoints(geohash.NewLocation(19.43265922422016, -99.13317967733457))
+	var buf bytes.Buffer
+	if assert.NoError(t, c.SVG(&buf)) {
+		svg := buf.String()
+		assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="800" height="400"`))
+		assert.Equal(t, 3, strings.Count(svg, "<rect x="), "Two cells and a region")
+		assert.Contains(t, svg, ">9g3w</text>")
+		assert.Contains(t, svg, ">9g3x</text>")
+		assert.Equal(t, 1, strings.Count(svg, "<circle"))
+		assert.True(t, strings.HasSuffix(svg, "</svg>\n"))
+	}
+	// Labels that do not fit are left out
+	c = New(100, 100, Equirectangular)
+	c.SetBounds(geohash.NewRegion(geohash.NewLocation(-90, -180), geohash.NewLocation(90, 180)))
+	c.AddGeohashes("9g3w81t7")
+	buf.Reset()
+	assert.NoError(t, c.SVG(&buf))
+	assert.NotContains(t, buf.String(), "<text")
+	// Labels are escaped
+	c = New(800, 400, Equirectangular)
+	c.SetBounds(geohash.NewRegion(geohash.NewLocation(-90, -180), geohash.NewLocation(90, 180)))
+	c.AddGeohashes("<")
+	buf.Reset()
+	assert.NoError(t, c.SVG(&buf))
+	assert.Contains(t, buf.String(), ">&lt;</text>")
+	assert.NotContains(t, buf.String(), "><</text>")
+}
+
+func TestPNG(t *testing.T) {
+	c := New(320, 240, WebMercator)
+	c.AddGeohashes("9g3w")
+	c.AddRegions(geohash.Decode("9g3"))
+	zocalo := geohash.NewLocation(19.43265922422016, -99.13317967733457)
+	c.AddPoints(zocalo)
+	var buf bytes.Buffer
+	if assert.NoError(t, c.PNG(&buf)) {
+		img, err := png.Decode(&buf)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 320, img.Bounds().Dx())
+			assert.Equal(t, 240, img.Bounds().Dy())
+			// The point is drawn
+			x, y := c.viewport().point(zocalo)
+			assert.Equal(t, color.NRGBAModel.Convert(pointFill), color.NRGBAModel.Convert(img.At(int(math.Round(x)), int(math.Round(y)))))
+			// The region outline is drawn
+			rect := c.viewport().rect(geohash.Decode("9g3")).image()
+			assert.Equal(t, color.NRGBAModel.Convert(regionLine), color.NRGBAModel.Convert(img.At(rect.Min.X, rect.Min.Y)))
+		}
+	}
+}
+
+func TestProjection(t *testing.T) {
+	x, y := Equirectangular.project(geohash.NewLocation(45, 90))
+	assert.Equal(t, 90.0, x)
+	assert.Equal(t, 45.0, y)
+	x, y = WebMercator.project(geohash.NewLocation(0, 90))
+	assert.Equal(t, 90.0, x)
+	assert.InDelta(t, 0.0, y, 1e-9)
+	// Stretched towards the poles, square world at the cut
+	_, y = WebMercator.project(geohash.NewLocation(45, 0))
+	assert.Greater(t, y, 45.0)
+	_, y = WebMercator.project(geohash.NewLocation(90, 0))
+	assert.InDelta(t, 180.0, y, 1e-9)
+}
+
+func TestViewport(t *testing.T) {
+	// Empty canvas shows the world, same scale on both axes
+	c := New(360, 360, Equirectangular)
+	v := c.viewport()
+	x, y := v.point(geohash.NewLocation(90, -180))
+	assert.InDelta(t, 0.0, x, 1e-9)
+	assert.InDelta(t, 90.0, y, 1e-9)
+	x, y = v.point(geohash.NewLocation(-90, 180))
+	assert.InDelta(t, 360.0, x, 1e-9)
+	assert.InDelta(t, 270.0, y, 1e-9)
+	// Fits the content with a margin
+	c.AddGeohashes("9g3w")
+	r := c.viewport().rect(geohash.Decode("9g3w"))
+	assert.Greater(t, r.x0, 0.0)
+	assert.Less(t, r.x1, 360.0)
+	assert.Greater(t, r.y0, 0.0)
+	assert.Less(t, r.y1, 360.0)
+}
@@ -2,17 +2,54 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 
 	bolt "go.etcd.io/bbolt"
 )
 
+// ErrNotFound is returned when there is no data stored in a key
+var ErrNotFound = errors.New("key not found")
+
+// ErrReservedName is returned when opening a database named like one of the object buckets
+var ErrReservedName = errors.New("database name is reserved for objects")
+
+var (
+	// objectsBucket stores objects under a composite key: geohash + "/" + id
+	objectsBucket = []byte("objects")
+	// objectIDsBucket is the secondary index from object ids to their composite keys
+	objectIDsBucket = []byte("objects_ids")
+)
+
+// Object is a record with a stable ID at a location, many objects can share a geohash
+type Object struct {
+	ID      string          `json:"id"`
+	Lat     float64         `json:"lat"`
+	Lon     float64         `json:"lon"`
+	Geohash string          `json:"geohash"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Key returns the composite key of the object, sorted by location and then by id
+func (obj Object) Key() string {
+	return obj.Geohash + "/" + obj.ID
+}
+
 // Database defines a simple Key/Value Store interface
 type Database interface {
 	Open() error
 	Close() error
-	Set(key, value string) error
-	Get(key string) string
-	GetAllByPrefix(prefix string) map[string]string
+	Set(ctx context.Context, key string, value []byte) error
+	Put(ctx context.Context, key string, value []byte) (created bool, err error)
+	Get(ctx context.Context, key string) ([]byte, error)
+	GetAllByPrefix(ctx context.Context, prefix string) (map[string][]byte, error)
+	Delete(ctx context.Context, key string) error
+	DeletePrefix(ctx context.Context, prefix string, dryRun bool) (int, error)
+	Scan(ctx context.Context, start, end string, fn func(key string, value []byte) error) error
+	ScanObjects(ctx context.Context, start, end string, fn func(obj Object) error) error
+	SetObject(ctx context.Context, obj Object) (created bool, err error)
+	GetObject(ctx context.Context, id string) (Object, error)
 }
 
 // BoltDB implements Database with a BoltDB backend
@@ -28,17 +65,22 @@ func NewBoltDB(name string) *BoltDB {
 
 // Open creates or opens a new BoltDB database file with the filename: <name>.db
 func (db *BoltDB) Open() error {
+	// The default bucket is named after the database, it must not be one of the objects ones
+	if db.name == string(objectsBucket) || db.name == string(objectIDsBucket) {
+		return ErrReservedName
+	}
 	// Create/Open database file
 	database, err := bolt.Open(db.name+".db", 0600, nil)
 	if err != nil {
 		return err
 	}
 	db.bolt = database
-	// Create default bucket
+	// Create default and objects buckets
 	err = db.bolt.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte(db.name))
-		if err != nil {
-			return err
+		for _, name := range [][]byte{[]byte(db.name), objectsBucket, objectIDsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
 		}
 		return nil
 	})
@@ -51,33 +93,219 @@ func (db *BoltDB) Close() error {
 }
 
 // Set stores data to the given geohash key
-func (db *BoltDB) Set(geohash, data string) error {
+func (db *BoltDB) Set(ctx context.Context, geohash string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	return db.bolt.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(db.name))
-		return b.Put([]byte(geohash), []byte(data))
+		return b.Put([]byte(geohash), data)
 	})
 }
 
-// Get returns the data (if any) stored in the geohash key
-func (db *BoltDB) Get(geohash string) string {
-	var data bytes.Buffer
-	db.bolt.View(func(tx *bolt.Tx) error {
+// Put stores data to the given geohash key, telling if it was created or replaced
+func (db *BoltDB) Put(ctx context.Context, geohash string, data []byte) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	created := false
+	err := db.bolt.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(db.name))
-		data.Write(b.Get([]byte(geohash)))
+		created = b.Get([]byte(geohash)) == nil
+		return b.Put([]byte(geohash), data)
+	})
+	return created, err
+}
+
+// Get returns the data stored in the geohash key, ErrNotFound if there is none
+func (db *BoltDB) Get(ctx context.Context, geohash string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var data []byte
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(db.name))
+		v := b.Get([]byte(geohash))
+		if v == nil {
+			return ErrNotFound
+		}
+		// Values are only valid during the transaction
+		data = append([]byte{}, v...)
 		return nil
 	})
-	return data.String()
+	return data, err
 }
 
 // GetAllByPrefix returns all the key/value pairs with the given prefix
-func (db *BoltDB) GetAllByPrefix(geohash string) map[string]string {
-	region := make(map[string]string)
-	db.bolt.View(func(tx *bolt.Tx) error {
+func (db *BoltDB) GetAllByPrefix(ctx context.Context, geohash string) (map[string][]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	region := make(map[string][]byte)
+	err := db.bolt.View(func(tx *bolt.Tx) error {
 		c := tx.Bucket([]byte(db.name)).Cursor()
 		for k, v := c.Seek([]byte(geohash)); k != nil && bytes.HasPrefix(k, []byte(geohash)); k, v = c.Next() {
-			region[string(k)] = string(v)
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			region[string(k)] = append([]byte{}, v...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return region, nil
+}
+
+// Delete removes the data stored in the geohash key, ErrNotFound if there is none
+func (db *BoltDB) Delete(ctx context.Context, geohash string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return db.bolt.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(db.name))
+		if b.Get([]byte(geohash)) == nil {
+			return ErrNotFound
+		}
+		return b.Delete([]byte(geohash))
+	})
+}
+
+// DeletePrefix removes all the keys with the given prefix in a single transaction and
+// returns how many. With dryRun the keys are only counted and nothing is removed.
+func (db *BoltDB) DeletePrefix(ctx context.Context, geohash string, dryRun bool) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	var keys [][]byte
+	collect := func(b *bolt.Bucket) error {
+		c := b.Cursor()
+		for k, _ := c.Seek([]byte(geohash)); k != nil && bytes.HasPrefix(k, []byte(geohash)); k, _ = c.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			keys = append(keys, append([]byte{}, k...))
+		}
+		return nil
+	}
+	// Counting only reads, so it doesn't take the writer lock
+	if dryRun {
+		if err := db.bolt.View(func(tx *bolt.Tx) error { return collect(tx.Bucket([]byte(db.name))) }); err != nil {
+			return 0, err
+		}
+		return len(keys), nil
+	}
+	err := db.bolt.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(db.name))
+		if err := collect(b); err != nil {
+			return err
+		}
+		// Deleting while iterating a cursor skips keys, so delete after collecting them
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(keys), nil
+}
+
+// Scan calls fn in key order for every key in the range [start, end), up to the last key
+// when end is empty. The value is only valid during the call, and an error from fn stops
+// the scan and is returned.
+func (db *BoltDB) Scan(ctx context.Context, start, end string, fn func(key string, value []byte) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return db.bolt.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte(db.name)).Cursor()
+		for k, v := c.Seek([]byte(start)); k != nil && (end == "" || string(k) < end); k, v = c.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(string(k), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ScanObjects calls fn in key order for every object with a composite key in the range
+// [start, end), up to the last object when end is empty. Geohash ranges find the objects
+// within them, as their keys start with the geohash. An error from fn stops the scan and
+// is returned.
+func (db *BoltDB) ScanObjects(ctx context.Context, start, end string, fn func(obj Object) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return db.bolt.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(objectsBucket).Cursor()
+		for k, v := c.Seek([]byte(start)); k != nil && (end == "" || string(k) < end); k, v = c.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			var obj Object
+			// json.Unmarshal copies the data out of the transaction
+			if err := json.Unmarshal(v, &obj); err != nil {
+				return err
+			}
+			if err := fn(obj); err != nil {
+				return err
+			}
 		}
 		return nil
 	})
-	return region
+}
+
+// SetObject stores the object, replacing the one with the same id if any. An object whose
+// geohash changed is moved to its new key in the same transaction, so it is never found
+// at both locations nor lost in between.
+func (db *BoltDB) SetObject(ctx context.Context, obj Object) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return false, err
+	}
+	created := false
+	err = db.bolt.Update(func(tx *bolt.Tx) error {
+		objects, ids := tx.Bucket(objectsBucket), tx.Bucket(objectIDsBucket)
+		key := []byte(obj.Key())
+		old := ids.Get([]byte(obj.ID))
+		created = old == nil
+		if !created && !bytes.Equal(old, key) {
+			if err := objects.Delete(old); err != nil {
+				return err
+			}
+		}
+		if err := objects.Put(key, data); err != nil {
+			return err
+		}
+		return ids.Put([]byte(obj.ID), key)
+	})
+	return created, err
+}
+
+// GetObject returns the object with the given id, ErrNotFound if there is none
+func (db *BoltDB) GetObject(ctx context.Context, id string) (Object, error) {
+	if err := ctx.Err(); err != nil {
+		return Object{}, err
+	}
+	var obj Object
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		key := tx.Bucket(objectIDsBucket).Get([]byte(id))
+		if key == nil {
+			return ErrNotFound
+		}
+		// json.Unmarshal copies the data out of the transaction
+		return json.Unmarshal(tx.Bucket(objectsBucket).Get(key), &obj)
+	})
+	return obj, err
 }
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"os"
 	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
 )
 
 func TestDatabaseSuccess(t *testing.T) {
@@ -16,20 +19,145 @@ func TestDatabaseSuccess(t *testing.T) {
 	defer func() {
 		os.Remove("testing.db")
 	}()
+	ctx := context.Background()
 	// Test Set Values
 	for i := 0; i < 100; i++ {
-		assert.NoError(t, db.Set(strconv.Itoa(i), strconv.Itoa(i)))
+		assert.NoError(t, db.Set(ctx, strconv.Itoa(i), []byte(strconv.Itoa(i))))
 	}
 	// Test Get Values
 	for i := 0; i < 100; i++ {
-		assert.Equal(t, strconv.Itoa(i), db.Get(strconv.Itoa(i)))
+		data, err := db.Get(ctx, strconv.Itoa(i))
+		assert.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(i), string(data))
 	}
+	// Test Get missing and empty values
+	_, err := db.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, db.Set(ctx, "empty", []byte{}))
+	data, err := db.Get(ctx, "empty")
+	assert.NoError(t, err)
+	assert.Empty(t, data)
 	// Test GetAllByPrefix
-	data := db.GetAllByPrefix("9")
-	for k, v := range data {
+	region, err := db.GetAllByPrefix(ctx, "9")
+	assert.NoError(t, err)
+	assert.Len(t, region, 11)
+	for k, v := range region {
 		assert.Equal(t, "9", k[0:1])
-		assert.Equal(t, k, v)
+		assert.Equal(t, k, string(v))
 	}
+	// Test Put create and replace
+	created, err := db.Put(ctx, "9g3w", []byte("a"))
+	assert.NoError(t, err)
+	assert.True(t, created)
+	created, err = db.Put(ctx, "9g3w", []byte("b"))
+	assert.NoError(t, err)
+	assert.False(t, created)
+	data, _ = db.Get(ctx, "9g3w")
+	assert.Equal(t, "b", string(data))
+	// Test Delete
+	assert.NoError(t, db.Delete(ctx, "9g3w"))
+	assert.ErrorIs(t, db.Delete(ctx, "9g3w"), ErrNotFound)
+	// Test DeletePrefix, dry run first while a writer holds the lock, which it must not wait for
+	writer, err := db.bolt.Begin(true)
+	assert.NoError(t, err)
+	count, err := db.DeletePrefix(ctx, "9", true)
+	assert.NoError(t, writer.Rollback())
+	assert.NoError(t, err)
+	assert.Equal(t, 11, count)
+	region, _ = db.GetAllByPrefix(ctx, "9")
+	assert.Len(t, region, 11)
+	count, err = db.DeletePrefix(ctx, "9", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 11, count)
+	region, _ = db.GetAllByPrefix(ctx, "9")
+	assert.Empty(t, region)
+	_, err = db.Get(ctx, "8")
+	assert.NoError(t, err, "Other prefixes are kept")
+	// Test Scan
+	for _, k := range []string{"b1", "b2", "b3", "c"} {
+		assert.NoError(t, db.Set(ctx, k, []byte(k)))
+	}
+	scan := func(start, end string) []string {
+		var keys []string
+		assert.NoError(t, db.Scan(ctx, start, end, func(key string, value []byte) error {
+			assert.Equal(t, key, string(value))
+			keys = append(keys, key)
+			return nil
+		}))
+		return keys
+	}
+	assert.Equal(t, []string{"b2", "b3"}, scan("b2", "c"))
+	assert.Equal(t, []string{"b1", "b2", "b3", "c"}, scan("b", "d"))
+	assert.Empty(t, scan("c0", "d"))
+	stop := errors.New("stop")
+	assert.ErrorIs(t, db.Scan(ctx, "b", "", func(key string, value []byte) error { return stop }), stop)
+	// Test objects, two in the same geohash
+	zocalo := Object{ID: "zocalo", Lat: 19.4326, Lon: -99.1331, Geohash: "9g3w81t7mqpx", Data: []byte(`"Zócalo"`)}
+	cathedral := Object{ID: "cathedral", Lat: 19.4326, Lon: -99.1331, Geohash: "9g3w81t7mqpx", Data: []byte(`"Cathedral"`)}
+	for _, obj := range []Object{zocalo, cathedral} {
+		created, err := db.SetObject(ctx, obj)
+		assert.NoError(t, err)
+		assert.True(t, created)
+	}
+	obj, err := db.GetObject(ctx, "zocalo")
+	assert.NoError(t, err)
+	assert.Equal(t, zocalo, obj)
+	obj, err = db.GetObject(ctx, "cathedral")
+	assert.NoError(t, err)
+	assert.Equal(t, cathedral, obj)
+	_, err = db.GetObject(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	// Moving an object removes its old key
+	zocalo.Geohash, zocalo.Lat = "9g3w81t7mqpz", 19.4327
+	created, err = db.SetObject(ctx, zocalo)
+	assert.NoError(t, err)
+	assert.False(t, created)
+	obj, _ = db.GetObject(ctx, "zocalo")
+	assert.Equal(t, zocalo, obj)
+	assert.NoError(t, db.bolt.View(func(tx *bolt.Tx) error {
+		var keys []string
+		tx.Bucket(objectsBucket).ForEach(func(k, v []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+		assert.Equal(t, []string{"9g3w81t7mqpx/cathedral", "9g3w81t7mqpz/zocalo"}, keys)
+		return nil
+	}))
+	// Scan objects within a geohash range
+	var ids []string
+	assert.NoError(t, db.ScanObjects(ctx, "9g3w81t7mqpz", "9g3w81t7mqq0", func(obj Object) error {
+		ids = append(ids, obj.ID)
+		return nil
+	}))
+	assert.Equal(t, []string{"zocalo"}, ids)
+	ids = nil
+	assert.NoError(t, db.ScanObjects(ctx, "9g3w", "", func(obj Object) error {
+		ids = append(ids, obj.ID)
+		return nil
+	}))
+	assert.Equal(t, []string{"cathedral", "zocalo"}, ids)
+	assert.ErrorIs(t, db.ScanObjects(ctx, "", "", func(Object) error { return stop }), stop)
+	// Test canceled context
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, db.Set(canceled, "1", []byte("1")), context.Canceled)
+	_, err = db.Get(canceled, "1")
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = db.GetAllByPrefix(canceled, "1")
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = db.Put(canceled, "1", []byte("1"))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, db.Delete(canceled, "1"), context.Canceled)
+	_, err = db.DeletePrefix(canceled, "1", false)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = db.DeletePrefix(canceled, "1", true)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, db.Scan(canceled, "", "", func(string, []byte) error { return nil }), context.Canceled)
+	assert.ErrorIs(t, db.ScanObjects(canceled, "", "", func(Object) error { return nil }), context.Canceled)
+	_, err = db.SetObject(canceled, zocalo)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = db.GetObject(canceled, "zocalo")
+	assert.ErrorIs(t, err, context.Canceled)
 	// Test Close
 	assert.NoError(t, db.Close())
 }
@@ -37,4 +165,8 @@ func TestDatabaseSuccess(t *testing.T) {
 func TestDatabaseFailure(t *testing.T) {
 	db := NewBoltDB("!/._.#?")
 	assert.Error(t, db.Open(), "Should fail because invalid characters")
+	// The default bucket would be one of the objects ones
+	for _, name := range []string{"objects", "objects_ids"} {
+		assert.ErrorIs(t, NewBoltDB(name).Open(), ErrReservedName, name)
+	}
 }
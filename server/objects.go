@@ -0,0 +1,86 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/phrozen/geohash"
+)
+
+// maxObjectID is the maximum length of a client provided object id
+const maxObjectID = 128
+
+// ObjectRequest is the body of POST /objects, the id is generated when empty
+type ObjectRequest struct {
+	ID   string          `json:"id"`
+	Lat  *float64        `json:"lat"`
+	Lon  *float64        `json:"lon"`
+	Data json.RawMessage `json:"data"`
+}
+
+// POST /objects
+func (app *App) postObjectHandler(c echo.Context) error {
+	var req ObjectRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Body must be a JSON object")
+	}
+	if req.Lat == nil || req.Lon == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Latitude and longitude are required")
+	}
+	if err := validateLocation(*req.Lat, *req.Lon); err != nil {
+		return err
+	}
+	if len(req.ID) > maxObjectID {
+		return echo.NewHTTPError(http.StatusBadRequest, "Object id must be at most 128 characters")
+	}
+	if req.ID == "" {
+		id, err := newObjectID()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		req.ID = id
+	}
+	obj := Object{
+		ID:      req.ID,
+		Lat:     *req.Lat,
+		Lon:     *req.Lon,
+		Geohash: geohash.Encode(*req.Lat, *req.Lon, defaultPrecision),
+		Data:    req.Data,
+	}
+	if len(obj.Data) == 0 {
+		obj.Data = json.RawMessage("null")
+	}
+	created, err := app.DB.SetObject(c.Request().Context(), obj)
+	if err != nil {
+		return storageError(err)
+	}
+	if created {
+		return c.JSON(http.StatusCreated, obj)
+	}
+	return c.JSON(http.StatusOK, obj)
+}
+
+// GET /objects/:id
+func (app *App) getObjectHandler(c echo.Context) error {
+	obj, err := app.DB.GetObject(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Object not found")
+	}
+	if err != nil {
+		return storageError(err)
+	}
+	return c.JSON(http.StatusOK, obj)
+}
+
+// newObjectID generates a random 128 bit id in hexadecimal
+func newObjectID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
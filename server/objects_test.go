@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostObject(t *testing.T) {
+	mock := &MockDB{db: make(map[string]string)}
+	app := NewApp(mock) // empty database
+	defer app.Shutdown()
+	// Create with a given id
+	rec, ctx := CreateContextRecord(http.MethodPost, "/objects", `{"id": "zocalo", "lat": 19.43265922422016, "lon": -99.13317967733457, "data": {"name": "Zócalo"}}`, "")
+	if assert.NoError(t, app.postObjectHandler(ctx)) {
+		assert.Equal(t, http.StatusCreated, rec.Code, "Status should be Created - 201")
+		assert.Equal(t, `{"id":"zocalo","lat":19.43265922422016,"lon":-99.13317967733457,"geohash":"9g3w81t7mqpx","data":{"name":"Zócalo"}}`+"\n", rec.Body.String())
+	}
+	// Same cell, another object
+	rec, ctx = CreateContextRecord(http.MethodPost, "/objects", `{"lat": 19.43265922422016, "lon": -99.13317967733457}`, "")
+	if assert.NoError(t, app.postObjectHandler(ctx)) {
+		assert.Equal(t, http.StatusCreated, rec.Code, "Status should be Created - 201")
+		var obj Object
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &obj))
+		assert.Len(t, obj.ID, 32, "Generated id")
+		assert.Equal(t, "9g3w81t7mqpx", obj.Geohash)
+		assert.Equal(t, "null", string(obj.Data))
+	}
+	assert.Len(t, mock.objects, 2)
+	// Move
+	rec, ctx = CreateContextRecord(http.MethodPost, "/objects", `{"id": "zocalo", "lat": 41.90216070037718, "lon": 12.453725061736066}`, "")
+	if assert.NoError(t, app.postObjectHandler(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code, "Status should be OK - 200")
+		assert.Equal(t, "sr2y7kh9bbfk", mock.objects["zocalo"].Geohash)
+	}
+}
+
+func TestPostObjectInvalid(t *testing.T) {
+	app := NewApp(&MockDB{db: make(map[string]string)}) // empty database
+	defer app.Shutdown()
+	invalid := map[string]string{
+		`[]`:                     "Body must be a JSON object",
+		`{"lon": 0}`:             "Latitude and longitude are required",
+		`{"lat": -91, "lon": 0}`: "Latitude must be between -90 and 90",
+		`{"lat": 0, "lon": 0, "id": "` + strings.Repeat("x", 129) + `"}`: "Object id must be at most 128 characters",
+	}
+	for body, message := range invalid {
+		_, ctx := CreateContextRecord(http.MethodPost, "/objects", body, "")
+		if err := app.postObjectHandler(ctx); assert.Error(t, err, body) {
+			he, ok := err.(*echo.HTTPError)
+			if assert.True(t, ok) {
+				assert.Equal(t, http.StatusBadRequest, he.Code, "Status should be Bad Request - 400")
+				assert.Equal(t, message, he.Message)
+			}
+		}
+	}
+}
+
+func TestGetObject(t *testing.T) {
+	app := NewApp(&MockDB{db: make(map[string]string), objects: map[string]Object{
+		"zocalo": {ID: "zocalo", Lat: 19.4326, Lon: -99.1331, Geohash: "9g3w81t7mqpx", Data: json.RawMessage(`"Zócalo"`)},
+	}})
+	defer app.Shutdown()
+	rec, ctx := CreateContextRecord(http.MethodGet, "/objects/:id", "", "")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("zocalo")
+	if assert.NoError(t, app.getObjectHandler(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code, "Status should be 200")
+		assert.Equal(t, `{"id":"zocalo","lat":19.4326,"lon":-99.1331,"geohash":"9g3w81t7mqpx","data":"Zócalo"}`+"\n", rec.Body.String())
+	}
+	_, ctx = CreateContextRecord(http.MethodGet, "/objects/:id", "", "")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("missing")
+	if err := app.getObjectHandler(ctx); assert.Error(t, err) {
+		he, ok := err.(*echo.HTTPError)
+		if assert.True(t, ok) {
+			assert.Equal(t, http.StatusNotFound, he.Code, "Status should be Not Found - 404")
+			assert.Equal(t, "Object not found", he.Message)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxPageSize is the most keys returned in a single page, also the default page size
+const maxPageSize = 1000
+
+// nextCursorHeader is the response header with the cursor of the next page, missing on the last one
+const nextCursorHeader = "X-Next-Cursor"
+
+// errPageFull stops scanning the database once a page is complete
+var errPageFull = errors.New("page full")
+
+// Page is the position and size of a page of results, in key order
+type Page struct {
+	After string // last key of the previous page, empty for the first one
+	Limit int
+}
+
+// queryPage parses the limit and after query parameters, the limit can be at most maxPageSize
+func queryPage(c echo.Context) (Page, error) {
+	limit, err := queryInt(c, "limit", maxPageSize)
+	if err != nil {
+		return Page{}, err
+	}
+	if limit > maxPageSize {
+		return Page{}, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Query parameter 'limit' must be at most %d", maxPageSize))
+	}
+	page := Page{Limit: limit}
+	if c.QueryParam("after") != "" {
+		after, err := base64.RawURLEncoding.DecodeString(c.QueryParam("after"))
+		if err != nil || len(after) == 0 {
+			return Page{}, echo.NewHTTPError(http.StatusBadRequest, "Invalid cursor in query parameter 'after'")
+		}
+		page.After = string(after)
+	}
+	return page, nil
+}
+
+// scanPage scans the sorted and disjoint key ranges in order, calling fn for the keys of the
+// page. It returns the cursor of the next page, empty when there are no more keys.
+func (app *App) scanPage(ctx context.Context, ranges []keyRange, page Page, fn func(key string, value []byte)) (string, error) {
+	last, more, err := app.scanBatch(ctx, ranges, page.After, page.Limit, fn)
+	if err != nil || !more {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(last)), nil
+}
+
+// scanBatch scans the sorted and disjoint key ranges in order, calling fn for up to limit keys
+// after the given one (from the start when empty). It returns the last key scanned, and if
+// there are more keys after it.
+func (app *App) scanBatch(ctx context.Context, ranges []keyRange, after string, limit int, fn func(key string, value []byte)) (last string, more bool, err error) {
+	count := 0
+	for _, r := range ranges {
+		if after != "" && r.end != "" && r.end <= after {
+			continue // the whole range was in previous batches
+		}
+		start := r.start
+		if after != "" && after >= start {
+			start = after + "\x00" // first key after the last one
+		}
+		err := app.DB.Scan(ctx, start, r.end, func(key string, value []byte) error {
+			if count == limit {
+				more = true
+				return errPageFull
+			}
+			fn(key, value)
+			count, last = count+1, key
+			return nil
+		})
+		if errors.Is(err, errPageFull) {
+			break
+		}
+		if err != nil {
+			return "", false, err
+		}
+	}
+	return last, more, nil
+}
+
+// setNextCursor adds the cursor of the next page to the response, if any
+func setNextCursor(c echo.Context, next string) {
+	if next != "" {
+		c.Response().Header().Set(nextCursorHeader, next)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegionPagination(t *testing.T) {
+	region := map[string]string{
+		"9":     "Precision 1",
+		"9e":    "Precision 2a",
+		"9ew":   "Precision 3a",
+		"9ewm":  "Precision 4a",
+		"9ewmq": "Precision 5a",
+		"9b":    "Precision 2b",
+		"9bn":   "Precision 3b",
+		"9bnr":  "Precision 4b",
+		"9bnrt": "Precision 5b",
+		"b":     "Outside",
+	}
+	app := NewApp(&MockDB{db: region})
+	defer app.Shutdown()
+	app.Configure()
+	var keys, pages []string
+	after := ""
+	for {
+		rec := serve(app, http.MethodGet, "/9/region?limit=2&after="+after, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var data map[string]string
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &data))
+		assert.LessOrEqual(t, len(data), 2)
+		page := make([]string, 0, len(data))
+		for k := range data {
+			page = append(page, k)
+		}
+		sort.Strings(page)
+		keys = append(keys, page...)
+		pages = append(pages, rec.Body.String())
+		if after = rec.Header().Get(nextCursorHeader); after == "" {
+			break
+		}
+	}
+	assert.Equal(t, []string{"9", "9b", "9bn", "9bnr", "9bnrt", "9e", "9ew", "9ewm", "9ewmq"}, keys)
+	assert.Len(t, pages, 5)
+	assert.Equal(t, `{"9":"Precision 1","9b":"Precision 2b"}`+"\n", pages[0])
+	// A page ending exactly with the last key has no next one
+	rec := serve(app, http.MethodGet, "/9/region?limit=9", "")
+	assert.Empty(t, rec.Header().Get(nextCursorHeader))
+	// Browsers can read the cursor
+	req := httptest.NewRequest(http.MethodGet, "/9/region?limit=1", nil)
+	req.Header.Set("Origin", "http://example.com")
+	rec = httptest.NewRecorder()
+	app.echo.ServeHTTP(rec, req)
+	assert.Equal(t, nextCursorHeader, rec.Header().Get("Access-Control-Expose-Headers"))
+	assert.NotEmpty(t, rec.Header().Get(nextCursorHeader))
+}
+
+func TestNeighboursPagination(t *testing.T) {
+	neighbours := map[string]string{
+		"9":   "Center",
+		"b":   "North West",
+		"c":   "North",
+		"c1":  "North 2",
+		"f":   "North East",
+		"8":   "West",
+		"d":   "East",
+		"2":   "South West",
+		"3":   "South",
+		"6":   "South East",
+		"6zz": "South East 3",
+	}
+	app := NewApp(&MockDB{db: neighbours})
+	defer app.Shutdown()
+	app.Configure()
+	data := make(map[string]map[string]string)
+	after, pages := "", 0
+	for {
+		rec := serve(app, http.MethodGet, "/9/neighbours?limit=5&after="+after, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var page map[string]map[string]string
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		for k, v := range page {
+			if data[k] == nil {
+				data[k] = make(map[string]string)
+			}
+			for key, value := range v {
+				data[k][key] = value
+			}
+		}
+		pages++
+		if after = rec.Header().Get(nextCursorHeader); after == "" {
+			break
+		}
+	}
+	assert.Equal(t, 2, pages)
+	assert.Equal(t, map[string]string{"c": "North", "c1": "North 2"}, data["n"])
+	assert.Equal(t, map[string]string{"6": "South East", "6zz": "South East 3"}, data["se"])
+	assert.Len(t, data, 8, "The center is not a neighbour")
+}
+
+func TestQueryPage(t *testing.T) {
+	page := func(query string) (Page, error) {
+		_, ctx := CreateContextRecord(http.MethodGet, "/:geohash/region", "", "9")
+		ctx.Request().URL.RawQuery = query
+		return queryPage(ctx)
+	}
+	p, err := page("")
+	assert.NoError(t, err)
+	assert.Equal(t, Page{Limit: maxPageSize}, p)
+	p, err = page("limit=1000&after=OWV3")
+	assert.NoError(t, err)
+	assert.Equal(t, Page{After: "9ew", Limit: maxPageSize}, p)
+	_, err = page("limit=1001")
+	if assert.Error(t, err) {
+		assert.Equal(t, "Query parameter 'limit' must be at most 1000", err.(*echo.HTTPError).Message)
+	}
+	for _, query := range []string{"limit=0", "limit=1001", "after=***", "after=="} {
+		_, err = page(query)
+		if assert.Error(t, err, query) {
+			assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/phrozen/geohash"
+)
+
+// defaultPrecision is the geohash precision used when the client does not set one
+const defaultPrecision = 12
+
+// PointRequest is the body of POST /points, data can be any JSON value
+type PointRequest struct {
+	Lat       *float64        `json:"lat"`
+	Lon       *float64        `json:"lon"`
+	Precision int             `json:"precision"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// PointResponse is the data stored in the geohash of a point
+type PointResponse struct {
+	Geohash string `json:"geohash"`
+	Data    string `json:"data"`
+}
+
+// POST /points
+func (app *App) postPointHandler(c echo.Context) error {
+	var req PointRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Body must be a JSON object")
+	}
+	if req.Lat == nil || req.Lon == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Latitude and longitude are required")
+	}
+	if err := validateLocation(*req.Lat, *req.Lon); err != nil {
+		return err
+	}
+	if req.Precision == 0 {
+		req.Precision = defaultPrecision
+	}
+	if err := validatePrecision(req.Precision); err != nil {
+		return err
+	}
+	if len(req.Data) == 0 || string(req.Data) == "null" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Data must not be empty")
+	}
+	// JSON strings are stored as their text, like the body of POST /:geohash
+	data := []byte(req.Data)
+	var text string
+	if json.Unmarshal(req.Data, &text) == nil {
+		data = []byte(text)
+	}
+	key := geohash.Encode(*req.Lat, *req.Lon, req.Precision)
+	if err := app.DB.Set(c.Request().Context(), key, data); err != nil {
+		return storageError(err)
+	}
+	return c.JSON(http.StatusCreated, key)
+}
+
+// GET /points?lat=&lon=&precision=
+func (app *App) getPointHandler(c echo.Context) error {
+	loc, err := queryLocation(c)
+	if err != nil {
+		return err
+	}
+	precision := defaultPrecision
+	if c.QueryParam("precision") != "" {
+		if precision, err = strconv.Atoi(c.QueryParam("precision")); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Precision must be an integer")
+		}
+		if err := validatePrecision(precision); err != nil {
+			return err
+		}
+	}
+	key := geohash.Encode(loc.Latitude(), loc.Longitude(), precision)
+	data, err := app.DB.Get(c.Request().Context(), key)
+	if errors.Is(err, ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Geohash not found")
+	}
+	if err != nil {
+		return storageError(err)
+	}
+	return c.JSON(http.StatusOK, PointResponse{Geohash: key, Data: string(data)})
+}
+
+// queryLocation parses and validates the lat and lon query parameters
+func queryLocation(c echo.Context) (geohash.Location, error) {
+	lat, err := queryFloat(c, "lat")
+	if err != nil {
+		return geohash.Location{}, err
+	}
+	lon, err := queryFloat(c, "lon")
+	if err != nil {
+		return geohash.Location{}, err
+	}
+	if err := validateLocation(lat, lon); err != nil {
+		return geohash.Location{}, err
+	}
+	return geohash.NewLocation(lat, lon), nil
+}
+
+// queryFloat parses a required numeric query parameter
+func queryFloat(c echo.Context, name string) (float64, error) {
+	v, err := strconv.ParseFloat(c.QueryParam(name), 64)
+	if err != nil {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Query parameter '%s' must be a number", name))
+	}
+	return v, nil
+}
+
+// validateLocation checks the coordinates are within the ranges of latitude and longitude,
+// the negated comparisons also reject NaN
+func validateLocation(lat, lon float64) error {
+	if !(lat >= -90 && lat <= 90) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Latitude must be between -90 and 90")
+	}
+	if !(lon >= -180 && lon <= 180) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+// validatePrecision checks the geohash precision is between 1 and 12 characters
+func validatePrecision(precision int) error {
+	if precision < 1 || precision > defaultPrecision {
+		return echo.NewHTTPError(http.StatusBadRequest, "Precision must be between 1 and 12")
+	}
+	return nil
+}
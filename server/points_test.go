@@ -0,0 +1,110 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostPoint(t *testing.T) {
+	app := NewApp(&MockDB{db: make(map[string]string)}) // empty database
+	defer app.Shutdown()
+	// Default precision, string data
+	rec, ctx := CreateContextRecord(http.MethodPost, "/points", `{"lat": 19.43265922422016, "lon": -99.13317967733457, "data": "Mexico - CDMX Zócalo"}`, "")
+	if assert.NoError(t, app.postPointHandler(ctx)) {
+		assert.Equal(t, http.StatusCreated, rec.Code, "Status should be Created - 201")
+		assert.Equal(t, "\"9g3w81t7mqpx\"\n", rec.Body.String(), "Return value should be the generated key")
+		assert.Equal(t, "Mexico - CDMX Zócalo", app.DB.(*MockDB).db["9g3w81t7mqpx"])
+	}
+	// Given precision, JSON data
+	rec, ctx = CreateContextRecord(http.MethodPost, "/points", `{"lat": -33.85684190426881, "lon": 151.21525191838856, "precision": 5, "data": {"name": "Opera House"}}`, "")
+	if assert.NoError(t, app.postPointHandler(ctx)) {
+		assert.Equal(t, http.StatusCreated, rec.Code, "Status should be Created - 201")
+		assert.Equal(t, "\"r3gx2\"\n", rec.Body.String(), "Return value should be the generated key")
+		assert.Equal(t, `{"name": "Opera House"}`, app.DB.(*MockDB).db["r3gx2"])
+	}
+}
+
+func TestPostPointInvalid(t *testing.T) {
+	app := NewApp(&MockDB{db: make(map[string]string)}) // empty database
+	defer app.Shutdown()
+	invalid := map[string]string{
+		`not json`:                                           "Body must be a JSON object",
+		`{"lat": 10, "data": "x"}`:                           "Latitude and longitude are required",
+		`{"lat": 91, "lon": 0, "data": "x"}`:                 "Latitude must be between -90 and 90",
+		`{"lat": 0, "lon": -181, "data": "x"}`:               "Longitude must be between -180 and 180",
+		`{"lat": 0, "lon": 0, "precision": 13, "data": "x"}`: "Precision must be between 1 and 12",
+		`{"lat": 0, "lon": 0}`:                               "Data must not be empty",
+		`{"lat": 0, "lon": 0, "data": null}`:                 "Data must not be empty",
+	}
+	for body, message := range invalid {
+		_, ctx := CreateContextRecord(http.MethodPost, "/points", body, "")
+		if err := app.postPointHandler(ctx); assert.Error(t, err, body) {
+			he, ok := err.(*echo.HTTPError)
+			if assert.True(t, ok) {
+				assert.Equal(t, http.StatusBadRequest, he.Code, "Status should be Bad Request - 400")
+				assert.Equal(t, message, he.Message)
+			}
+		}
+	}
+	assert.Empty(t, app.DB.(*MockDB).db)
+}
+
+func TestGetPoint(t *testing.T) {
+	app := NewApp(&MockDB{db: map[string]string{
+		"9g3w81t7mqpx": "Mexico - CDMX Zócalo",
+		"9g3w8":        "Mexico - CDMX Centro",
+	}})
+	defer app.Shutdown()
+	queries := map[string]string{
+		"lat=19.43265922422016&lon=-99.13317967733457":             `{"geohash":"9g3w81t7mqpx","data":"Mexico - CDMX Zócalo"}`,
+		"lat=19.43265922422016&lon=-99.13317967733457&precision=5": `{"geohash":"9g3w8","data":"Mexico - CDMX Centro"}`,
+	}
+	for query, body := range queries {
+		rec, ctx := CreateContextRecord(http.MethodGet, "/points", "", "")
+		ctx.Request().URL.RawQuery = query
+		if assert.NoError(t, app.getPointHandler(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code, "Status should be 200")
+			assert.Equal(t, body+"\n", rec.Body.String())
+		}
+	}
+	failures := map[string]int{
+		"lat=1&lon=1":                http.StatusNotFound,
+		"lat=north&lon=1":            http.StatusBadRequest,
+		"lon=1":                      http.StatusBadRequest,
+		"lat=1&lon=200":              http.StatusBadRequest,
+		"lat=NaN&lon=NaN":            http.StatusBadRequest,
+		"lat=1&lon=Inf":              http.StatusBadRequest,
+		"lat=1&lon=1&precision=zero": http.StatusBadRequest,
+		"lat=1&lon=1&precision=0":    http.StatusBadRequest,
+	}
+	for query, code := range failures {
+		_, ctx := CreateContextRecord(http.MethodGet, "/points", "", "")
+		ctx.Request().URL.RawQuery = query
+		if err := app.getPointHandler(ctx); assert.Error(t, err, query) {
+			he, ok := err.(*echo.HTTPError)
+			if assert.True(t, ok) {
+				assert.Equal(t, code, he.Code, query)
+			}
+		}
+	}
+}
+
+func TestPointsRouting(t *testing.T) {
+	app := NewApp(&MockDB{db: make(map[string]string)}) // empty database
+	defer app.Shutdown()
+	app.Configure()
+	// Not mistaken for a geohash (which would be invalid)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/points", strings.NewReader(`{"lat": 0, "lon": 0, "precision": 1, "data": "x"}`))
+	app.echo.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	// Geohash routes are still validated
+	rec = httptest.NewRecorder()
+	app.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/abc", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/phrozen/geohash"
+)
+
+// maxCoverCells is the most geohash cells used to cover a search area, the precision
+// of the cover is the highest that keeps it within this limit
+const maxCoverCells = 32
+
+const (
+	// defaultNearest is how many results the nearest search returns when the client does not set k
+	defaultNearest = 10
+	// maxNearest is the most results the nearest search can return
+	maxNearest = 1000
+	// nearestPrecision is the cell size (about 1.2 x 0.6 km) where the nearest search starts
+	nearestPrecision = 6
+)
+
+// SearchResult is a stored geohash found by a search, located at the center of its cell,
+// or an object at its own location with its id and its JSON data as text
+type SearchResult struct {
+	ID      string  `json:"id,omitempty"`
+	Geohash string  `json:"geohash"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+	Data    string  `json:"data"`
+}
+
+// keyRange is a range of keys [start, end) scanned in the database, end is empty for no limit
+type keyRange struct {
+	start, end string
+}
+
+// GET /search/bbox?minLat=&minLon=&maxLat=&maxLon=
+func (app *App) getBBoxSearchHandler(c echo.Context) error {
+	var bounds [4]float64
+	for i, name := range []string{"minLat", "minLon", "maxLat", "maxLon"} {
+		v, err := queryFloat(c, name)
+		if err != nil {
+			return err
+		}
+		bounds[i] = v
+	}
+	minLat, minLon, maxLat, maxLon := bounds[0], bounds[1], bounds[2], bounds[3]
+	if err := validateLocation(minLat, minLon); err != nil {
+		return err
+	}
+	if err := validateLocation(maxLat, maxLon); err != nil {
+		return err
+	}
+	if minLat > maxLat {
+		return echo.NewHTTPError(http.StatusBadRequest, "minLat must not be greater than maxLat")
+	}
+	// A box with minLon greater than maxLon crosses the antimeridian
+	inside := func(loc geohash.Location) bool {
+		if loc.Latitude() < minLat || loc.Latitude() > maxLat {
+			return false
+		}
+		if minLon <= maxLon {
+			return loc.Longitude() >= minLon && loc.Longitude() <= maxLon
+		}
+		return loc.Longitude() >= minLon || loc.Longitude() <= maxLon
+	}
+	cover := coverBox(geohash.NewLocation(minLat, minLon), geohash.NewLocation(maxLat, maxLon))
+	results := []SearchResult{}
+	err := app.scanResults(c.Request().Context(), coverRanges(cover), func(r SearchResult, loc geohash.Location) error {
+		if inside(loc) {
+			results = append(results, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return storageError(err)
+	}
+	return c.JSON(http.StatusOK, results)
+}
+
+// NearbyResult is a search result with its distance in meters to the searched location
+type NearbyResult struct {
+	SearchResult
+	Distance float64 `json:"distance"`
+}
+
+// GET /search/radius?lat=&lon=&meters=&limit=
+func (app *App) getRadiusSearchHandler(c echo.Context) error {
+	center, err := queryLocation(c)
+	if err != nil {
+		return err
+	}
+	meters, err := queryFloat(c, "meters")
+	if err != nil {
+		return err
+	}
+	if !(meters > 0) || math.IsInf(meters, 1) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Meters must be a positive number")
+	}
+	limit, err := queryInt(c, "limit", 0)
+	if err != nil {
+		return err
+	}
+	cover := finestCover(func(precision int) geohash.Seq[string] {
+		return geohash.Slice(geohash.CoverCircle(center, meters, precision))
+	})
+	results := []NearbyResult{}
+	err = app.scanResults(c.Request().Context(), coverRanges(cover), func(r SearchResult, loc geohash.Location) error {
+		if d := geohash.Distance(center, loc); d <= meters {
+			results = append(results, NearbyResult{SearchResult: r, Distance: d})
+		}
+		return nil
+	})
+	if err != nil {
+		return storageError(err)
+	}
+	// Ranges are scanned in key order, so ties keep it
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Distance < results[j].Distance
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return c.JSON(http.StatusOK, results)
+}
+
+// GET /search/nearest?lat=&lon=&k=&maxMeters=
+func (app *App) getNearestSearchHandler(c echo.Context) error {
+	center, err := queryLocation(c)
+	if err != nil {
+		return err
+	}
+	k, err := queryInt(c, "k", defaultNearest)
+	if err != nil {
+		return err
+	}
+	if k > maxNearest {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Query parameter 'k' must be at most %d", maxNearest))
+	}
+	maxMeters := math.Inf(1)
+	if c.QueryParam("maxMeters") != "" {
+		if maxMeters, err = queryFloat(c, "maxMeters"); err != nil {
+			return err
+		}
+		if !(maxMeters > 0) {
+			return echo.NewHTTPError(http.StatusBadRequest, "maxMeters must be a positive number")
+		}
+	}
+	ctx := c.Request().Context()
+	lookup := func(prefix string) ([]geohash.Item[SearchResult], error) {
+		var items []geohash.Item[SearchResult]
+		err := app.scanResults(ctx, coverRanges([]string{prefix}), func(r SearchResult, loc geohash.Location) error {
+			// Coarser keys belong to the cell holding the center of theirs
+			if len(r.Geohash) < len(prefix) && geohash.Encode(loc.Latitude(), loc.Longitude(), len(prefix)) != prefix {
+				return nil
+			}
+			items = append(items, geohash.Item[SearchResult]{Location: loc, Value: r})
+			return nil
+		})
+		return items, err
+	}
+	nearest, err := geohash.NearestWithin(center, k, maxMeters, nearestPrecision, lookup)
+	if err != nil {
+		return storageError(err)
+	}
+	results := make([]NearbyResult, len(nearest))
+	for i, item := range nearest {
+		results[i] = NearbyResult{SearchResult: item.Value, Distance: geohash.Distance(center, item.Location)}
+	}
+	return c.JSON(http.StatusOK, results)
+}
+
+// CountResponse is the number of results of a search
+type CountResponse struct {
+	Count int `json:"count"`
+}
+
+// POST /search/polygon[?count=true]
+func (app *App) postPolygonSearchHandler(c echo.Context) error {
+	body, err := readBody(c)
+	if err != nil {
+		return err
+	}
+	polygons, err := geohash.ParseGeoJSON(body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Body must be a GeoJSON Polygon or MultiPolygon")
+	}
+	for _, p := range polygons {
+		if err := validateLocation(p.Bounds().Min().Latitude(), p.Bounds().Min().Longitude()); err != nil {
+			return err
+		}
+		if err := validateLocation(p.Bounds().Max().Latitude(), p.Bounds().Max().Longitude()); err != nil {
+			return err
+		}
+	}
+	cover := finestCover(func(precision int) geohash.Seq[string] {
+		return func(yield func(string) bool) {
+			for _, p := range polygons {
+				inside, boundary := geohash.CoverPolygon(p, precision)
+				for _, cell := range append(inside, boundary...) {
+					if !yield(cell) {
+						return
+					}
+				}
+			}
+		}
+	})
+	count := c.QueryParam("count") == "true"
+	results := []SearchResult{}
+	total := 0
+	err = app.scanResults(c.Request().Context(), coverRanges(cover), func(r SearchResult, loc geohash.Location) error {
+		for _, p := range polygons {
+			if p.Contains(loc) {
+				total++
+				if !count {
+					results = append(results, r)
+				}
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return storageError(err)
+	}
+	if count {
+		return c.JSON(http.StatusOK, CountResponse{Count: total})
+	}
+	return c.JSON(http.StatusOK, results)
+}
+
+// queryInt parses an optional positive integer query parameter, def when missing
+func queryInt(c echo.Context, name string, def int) (int, error) {
+	if c.QueryParam(name) == "" {
+		return def, nil
+	}
+	v, err := strconv.Atoi(c.QueryParam(name))
+	if err != nil || v <= 0 {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Query parameter '%s' must be a positive integer", name))
+	}
+	return v, nil
+}
+
+// coverBox returns the cells covering the box spanned by its South-West and North-East corners.
+// A box crossing the antimeridian (West longitude greater than East) is covered in two halves.
+func coverBox(sw, ne geohash.Location) []string {
+	boxes := [][2]geohash.Location{{sw, ne}}
+	if sw.Longitude() > ne.Longitude() {
+		boxes = [][2]geohash.Location{
+			{sw, geohash.NewLocation(ne.Latitude(), 180)},
+			{geohash.NewLocation(sw.Latitude(), -180), ne},
+		}
+	}
+	return finestCover(func(precision int) geohash.Seq[string] {
+		return func(yield func(string) bool) {
+			for _, box := range boxes {
+				more := true
+				geohash.Grid(
+					geohash.Encode(box[0].Latitude(), box[0].Longitude(), precision),
+					geohash.Encode(box[1].Latitude(), box[1].Longitude(), precision),
+					geohash.ZOrder,
+				)(func(cell string) bool {
+					more = yield(cell)
+					return more
+				})
+				if !more {
+					return
+				}
+			}
+		}
+	})
+}
+
+// finestCover returns the cover with the highest precision that needs at most maxCoverCells
+// distinct cells, the whole world (the empty geohash) when not even precision 1 does
+func finestCover(cover func(precision int) geohash.Seq[string]) []string {
+	finest := []string{""}
+	for precision := 1; precision <= defaultPrecision; precision++ {
+		var cells []string
+		seen := make(map[string]bool)
+		cover(precision)(func(cell string) bool {
+			if !seen[cell] {
+				seen[cell] = true
+				cells = append(cells, cell)
+			}
+			return len(cells) <= maxCoverCells
+		})
+		if len(cells) > maxCoverCells {
+			break
+		}
+		finest = cells
+	}
+	return finest
+}
+
+// coverRanges converts the cells of a cover into sorted, merged key ranges. Besides every
+// key within the cells, the ranges include the keys of the coarser cells containing them.
+func coverRanges(cells []string) []keyRange {
+	seen := make(map[string]bool)
+	var ranges []keyRange
+	for _, cell := range cells {
+		for i := 1; i < len(cell); i++ {
+			if parent := cell[:i]; !seen[parent] {
+				seen[parent] = true
+				// No key sorts between a geohash and its first child
+				ranges = append(ranges, keyRange{start: parent, end: parent + geohash.Alphabet[:1]})
+			}
+		}
+		ranges = append(ranges, keyRange{start: cell, end: geohash.PrefixEnd(cell)})
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].start < ranges[j].start
+	})
+	var merged []keyRange
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && (merged[n-1].end == "" || r.start <= merged[n-1].end) {
+			if merged[n-1].end != "" && (r.end == "" || r.end > merged[n-1].end) {
+				merged[n-1].end = r.end
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// scanRanges scans every key range in order with a cursor seek to the start of each one
+func (app *App) scanRanges(ctx context.Context, ranges []keyRange, fn func(key string, value []byte) error) error {
+	for _, r := range ranges {
+		if err := app.DB.Scan(ctx, r.start, r.end, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanResults scans the stored geohashes and then the objects within the key ranges, calling
+// fn with each one and its location: the center of the cell for geohashes, the exact one for
+// objects.
+func (app *App) scanResults(ctx context.Context, ranges []keyRange, fn func(r SearchResult, loc geohash.Location) error) error {
+	err := app.scanRanges(ctx, ranges, func(key string, value []byte) error {
+		if !geohash.Valid(key) {
+			return nil
+		}
+		loc := geohash.Decode(key).Center()
+		return fn(SearchResult{Geohash: key, Lat: loc.Latitude(), Lon: loc.Longitude(), Data: string(value)}, loc)
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range ranges {
+		err := app.DB.ScanObjects(ctx, r.start, r.end, func(obj Object) error {
+			loc := geohash.NewLocation(obj.Lat, obj.Lon)
+			return fn(SearchResult{ID: obj.ID, Geohash: obj.Geohash, Lat: obj.Lat, Lon: obj.Lon, Data: string(obj.Data)}, loc)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
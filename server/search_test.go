@@ -0,0 +1,292 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/phrozen/geohash"
+	"github.com/stretchr/testify/assert"
+)
+
+// searchDB has points around Mexico City, Rome and both sides of the antimeridian in Fiji
+func searchDB() *MockDB {
+	return &MockDB{db: map[string]string{
+		"9g3w81t7mqpx":                       "Zócalo",
+		"9g3w":                               "Mexico City",
+		"9g":                                 "Mexico",
+		"sr2y7kh9bbfk":                       "Rome",
+		geohash.Encode(-17.71, 178.06, 12):   "Suva",
+		geohash.Encode(-16.79, -179.99, 12):  "Taveuni",
+		geohash.Encode(-21.13, -175.20, 12):  "Nuku'alofa",
+		geohash.Encode(-17.75, -177.45, 12):  "Lau",
+		geohash.Encode(-17.75, 179.999, 12):  "Antimeridian",
+		geohash.Encode(-17.75, -179.999, 12): "Antimeridian",
+	}}
+}
+
+// serve sends a request to a configured app, returning the recorded response
+func serve(app *App, method, target, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	app.echo.ServeHTTP(rec, req)
+	return rec
+}
+
+// names decodes a list of search results into the data of each one
+func names(t *testing.T, rec *httptest.ResponseRecorder) []string {
+	var results []SearchResult
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &results))
+	names := []string{}
+	for _, r := range results {
+		names = append(names, r.Data)
+	}
+	return names
+}
+
+func TestBBoxSearch(t *testing.T) {
+	app := NewApp(searchDB())
+	defer app.Shutdown()
+	app.Configure()
+	// Coarse keys are only found when the center of their cell is inside the box
+	rec := serve(app, http.MethodGet, "/search/bbox?minLat=19&minLon=-100&maxLat=20&maxLon=-98.5", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"Mexico City", "Zócalo"}, names(t, rec))
+	var results []SearchResult
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &results))
+	assert.Equal(t, "9g3w81t7mqpx", results[1].Geohash)
+	assert.InDelta(t, 19.4326, results[1].Lat, 1e-4)
+	assert.InDelta(t, -99.1331, results[1].Lon, 1e-4)
+	// Crossing the antimeridian
+	rec = serve(app, http.MethodGet, "/search/bbox?minLat=-18&minLon=178&maxLat=-16&maxLon=-179", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.ElementsMatch(t, []string{"Suva", "Taveuni", "Antimeridian", "Antimeridian"}, names(t, rec))
+	// Crossing the antimeridian with both longitudes in the same coarse column, all but 1° of the world
+	rec = serve(app, http.MethodGet, "/search/bbox?minLat=-90&minLon=10&maxLat=90&maxLon=9", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, names(t, rec), 10)
+	rec = serve(app, http.MethodGet, "/search/bbox?minLat=-10&minLon=10&maxLat=20&maxLon=9", "")
+	assert.ElementsMatch(t, []string{"Mexico", "Mexico City", "Zócalo"}, names(t, rec))
+	// The whole world
+	rec = serve(app, http.MethodGet, "/search/bbox?minLat=-90&minLon=-180&maxLat=90&maxLon=180", "")
+	assert.Len(t, names(t, rec), 10)
+	// Nothing found
+	rec = serve(app, http.MethodGet, "/search/bbox?minLat=0&minLon=0&maxLat=1&maxLon=1", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "[]\n", rec.Body.String())
+}
+
+func TestBBoxSearchInvalid(t *testing.T) {
+	app := NewApp(searchDB())
+	defer app.Shutdown()
+	app.Configure()
+	invalid := map[string]string{
+		"minLon=0&maxLat=1&maxLon=1":            "Query parameter 'minLat' must be a number",
+		"minLat=0&minLon=0&maxLat=1&maxLon=x":   "Query parameter 'maxLon' must be a number",
+		"minLat=-91&minLon=0&maxLat=1&maxLon=1": "Latitude must be between -90 and 90",
+		"minLat=0&minLon=0&maxLat=1&maxLon=181": "Longitude must be between -180 and 180",
+		"minLat=NaN&minLon=0&maxLat=1&maxLon=1": "Latitude must be between -90 and 90",
+		"minLat=0&minLon=0&maxLat=1&maxLon=NaN": "Longitude must be between -180 and 180",
+		"minLat=1&minLon=0&maxLat=0&maxLon=1":   "minLat must not be greater than maxLat",
+	}
+	for query, message := range invalid {
+		rec := serve(app, http.MethodGet, "/search/bbox?"+query, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		assert.Contains(t, rec.Body.String(), message, query)
+	}
+	app.DB.(*MockDB).err = errors.New("disk on fire")
+	rec := serve(app, http.MethodGet, "/search/bbox?minLat=0&minLon=0&maxLat=1&maxLon=1", "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestCoverRanges(t *testing.T) {
+	assert.Equal(t, []keyRange{
+		{"9", "90"}, {"9g", "9g0"},
+		{"9g3", "9g30"}, {"9g3w", "9g3y"},
+		{"9u", "9u1"},
+	}, coverRanges([]string{"9g3w", "9g3x", "9u0"}))
+	assert.Equal(t, []keyRange{{"y", ""}}, coverRanges([]string{"y", "z"}))
+}
+
+func TestRadiusSearch(t *testing.T) {
+	app := NewApp(searchDB())
+	defer app.Shutdown()
+	app.Configure()
+	rec := serve(app, http.MethodGet, "/search/radius?lat=19.4326&lon=-99.1331&meters=1000", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"Zócalo"}, names(t, rec))
+	// Sorted by distance
+	rec = serve(app, http.MethodGet, "/search/radius?lat=19.4326&lon=-99.1331&meters=50000", "")
+	var results []NearbyResult
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &results))
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "Zócalo", results[0].Data)
+		assert.Less(t, results[0].Distance, 20.0)
+		assert.Equal(t, "Mexico City", results[1].Data)
+		assert.InDelta(t, 17700, results[1].Distance, 100)
+	}
+	rec = serve(app, http.MethodGet, "/search/radius?lat=19.4326&lon=-99.1331&meters=50000&limit=1", "")
+	assert.Equal(t, []string{"Zócalo"}, names(t, rec))
+	// Crossing the antimeridian
+	rec = serve(app, http.MethodGet, "/search/radius?lat=-17.75&lon=180&meters=5000", "")
+	assert.Equal(t, []string{"Antimeridian", "Antimeridian"}, names(t, rec))
+	rec = serve(app, http.MethodGet, "/search/radius?lat=-17.75&lon=180&meters=300000", "")
+	assert.Equal(t, []string{"Antimeridian", "Antimeridian", "Taveuni", "Suva", "Lau"}, names(t, rec))
+	// Nothing found
+	rec = serve(app, http.MethodGet, "/search/radius?lat=0&lon=0&meters=1000", "")
+	assert.Equal(t, "[]\n", rec.Body.String())
+}
+
+func TestRadiusSearchInvalid(t *testing.T) {
+	app := NewApp(searchDB())
+	defer app.Shutdown()
+	app.Configure()
+	invalid := map[string]string{
+		"lon=0&meters=1":                "Query parameter 'lat' must be a number",
+		"lat=0&lon=0":                   "Query parameter 'meters' must be a number",
+		"lat=0&lon=0&meters=0":          "Meters must be a positive number",
+		"lat=0&lon=0&meters=NaN":        "Meters must be a positive number",
+		"lat=NaN&lon=0&meters=1":        "Latitude must be between -90 and 90",
+		"lat=0&lon=0&meters=1&limit=-1": "Query parameter 'limit' must be a positive integer",
+		"lat=0&lon=0&meters=1&limit=x":  "Query parameter 'limit' must be a positive integer",
+	}
+	for query, message := range invalid {
+		rec := serve(app, http.MethodGet, "/search/radius?"+query, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		assert.Contains(t, rec.Body.String(), message, query)
+	}
+	app.DB.(*MockDB).err = errors.New("disk on fire")
+	rec := serve(app, http.MethodGet, "/search/radius?lat=0&lon=0&meters=1", "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestPolygonSearch(t *testing.T) {
+	app := NewApp(searchDB())
+	defer app.Shutdown()
+	app.Configure()
+	mexico := `[[-100, 19], [-98.5, 19], [-98.5, 20], [-100, 20], [-100, 19]]`
+	zocalo := `[[-99.2, 19.4], [-99.1, 19.4], [-99.1, 19.5], [-99.2, 19.5], [-99.2, 19.4]]`
+	suva := `[[178, -18], [178.5, -18], [178.5, -17.5], [178, -17.5], [178, -18]]`
+	rec := serve(app, http.MethodPost, "/search/polygon", `{"type": "Polygon", "coordinates": [`+mexico+`]}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"Mexico City", "Zócalo"}, names(t, rec))
+	// Holes are respected
+	rec = serve(app, http.MethodPost, "/search/polygon", `{"type": "Polygon", "coordinates": [`+mexico+`, `+zocalo+`]}`)
+	assert.Equal(t, []string{"Mexico City"}, names(t, rec))
+	rec = serve(app, http.MethodPost, "/search/polygon", `{"type": "MultiPolygon", "coordinates": [[`+mexico+`], [`+suva+`]]}`)
+	assert.Equal(t, []string{"Mexico City", "Zócalo", "Suva"}, names(t, rec))
+	// Only the count
+	rec = serve(app, http.MethodPost, "/search/polygon?count=true", `{"type": "MultiPolygon", "coordinates": [[`+mexico+`, `+zocalo+`], [`+suva+`]]}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"count":2}`+"\n", rec.Body.String())
+	rec = serve(app, http.MethodPost, "/search/polygon", `{"type": "Polygon", "coordinates": [`+zocalo+`]}`)
+	assert.Equal(t, []string{"Zócalo"}, names(t, rec))
+	// Nothing found
+	rec = serve(app, http.MethodPost, "/search/polygon", `{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}`)
+	assert.Equal(t, "[]\n", rec.Body.String())
+}
+
+func TestPolygonSearchInvalid(t *testing.T) {
+	app := NewApp(searchDB())
+	defer app.Shutdown()
+	app.Configure()
+	invalid := map[string]string{
+		``: "Body must have non-zero length",
+		`{"type": "Point", "coordinates": [0, 0]}`:                                 "Body must be a GeoJSON Polygon or MultiPolygon",
+		`{"type": "Polygon", "coordinates": [[[0, 0]]]}`:                           "Body must be a GeoJSON Polygon or MultiPolygon",
+		`{"type": "Polygon", "coordinates": [[[0, 0], [0, 95], [1, 95], [0, 0]]]}`: "Latitude must be between -90 and 90",
+	}
+	for body, message := range invalid {
+		rec := serve(app, http.MethodPost, "/search/polygon", body)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+		assert.Contains(t, rec.Body.String(), message, body)
+	}
+	app.DB.(*MockDB).err = errors.New("disk on fire")
+	rec := serve(app, http.MethodPost, "/search/polygon", `{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}`)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestNearestSearch(t *testing.T) {
+	app := NewApp(searchDB())
+	defer app.Shutdown()
+	app.Configure()
+	rec := serve(app, http.MethodGet, "/search/nearest?lat=19.4326&lon=-99.1331&k=3", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var results []NearbyResult
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &results))
+	if assert.Len(t, results, 3) {
+		assert.Equal(t, "Zócalo", results[0].Data)
+		assert.Less(t, results[0].Distance, 20.0)
+		assert.Equal(t, "Mexico City", results[1].Data)
+		assert.InDelta(t, 17700, results[1].Distance, 100)
+		// Far away, but still the third closest
+		assert.Equal(t, "Mexico", results[2].Data)
+	}
+	// Defaults to 10, more than there are
+	rec = serve(app, http.MethodGet, "/search/nearest?lat=-17.75&lon=180", "")
+	assert.Equal(t, []string{"Antimeridian", "Antimeridian", "Taveuni", "Suva", "Lau", "Nuku'alofa", "Zócalo", "Mexico City", "Mexico", "Rome"}, names(t, rec))
+	// Cutoff
+	rec = serve(app, http.MethodGet, "/search/nearest?lat=-17.75&lon=180&k=5&maxMeters=150000", "")
+	assert.Equal(t, []string{"Antimeridian", "Antimeridian", "Taveuni"}, names(t, rec))
+	rec = serve(app, http.MethodGet, "/search/nearest?lat=0&lon=0&maxMeters=1000", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "[]\n", rec.Body.String())
+}
+
+func TestNearestSearchInvalid(t *testing.T) {
+	app := NewApp(searchDB())
+	defer app.Shutdown()
+	app.Configure()
+	invalid := map[string]string{
+		"lat=0":                   "Query parameter 'lon' must be a number",
+		"lat=0&lon=0&k=0":         "Query parameter 'k' must be a positive integer",
+		"lat=0&lon=0&k=1001":      "Query parameter 'k' must be at most 1000",
+		"lat=0&lon=0&maxMeters=x": "Query parameter 'maxMeters' must be a number",
+		"lat=0&lon=0&maxMeters=0": "maxMeters must be a positive number",
+		"lat=0&lon=NaN":           "Longitude must be between -180 and 180",
+	}
+	for query, message := range invalid {
+		rec := serve(app, http.MethodGet, "/search/nearest?"+query, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		assert.Contains(t, rec.Body.String(), message, query)
+	}
+	app.DB.(*MockDB).err = errors.New("disk on fire")
+	rec := serve(app, http.MethodGet, "/search/nearest?lat=0&lon=0", "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestSearchObjects(t *testing.T) {
+	app := NewApp(searchDB())
+	defer app.Shutdown()
+	app.Configure()
+	rec := serve(app, http.MethodPost, "/objects", `{"id": "bellas-artes", "lat": 19.4352, "lon": -99.1412, "data": {"name":"Bellas Artes"}}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	// Objects are found at their own location, after the geohashes
+	rec = serve(app, http.MethodGet, "/search/bbox?minLat=19&minLon=-100&maxLat=20&maxLon=-98.5", "")
+	assert.Equal(t, []string{"Mexico City", "Zócalo", `{"name":"Bellas Artes"}`}, names(t, rec))
+	var results []SearchResult
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &results))
+	if assert.Len(t, results, 3) {
+		assert.Empty(t, results[1].ID)
+		expected := SearchResult{ID: "bellas-artes", Geohash: geohash.Encode(19.4352, -99.1412, 12), Lat: 19.4352, Lon: -99.1412, Data: `{"name":"Bellas Artes"}`}
+		assert.Equal(t, expected, results[2])
+	}
+	rec = serve(app, http.MethodGet, "/search/bbox?minLat=19.434&minLon=-99.142&maxLat=19.436&maxLon=-99.140", "")
+	assert.Equal(t, []string{`{"name":"Bellas Artes"}`}, names(t, rec))
+	rec = serve(app, http.MethodGet, "/search/radius?lat=19.4326&lon=-99.1331&meters=2000", "")
+	assert.Equal(t, []string{"Zócalo", `{"name":"Bellas Artes"}`}, names(t, rec))
+	rec = serve(app, http.MethodPost, "/search/polygon", `{"type": "Polygon", "coordinates": [[[-99.15, 19.43], [-99.14, 19.43], [-99.14, 19.44], [-99.15, 19.44], [-99.15, 19.43]]]}`)
+	assert.Equal(t, []string{`{"name":"Bellas Artes"}`}, names(t, rec))
+	rec = serve(app, http.MethodGet, "/search/nearest?lat=19.4326&lon=-99.1331&k=2", "")
+	var nearest []NearbyResult
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &nearest))
+	if assert.Len(t, nearest, 2) {
+		assert.Equal(t, "Zócalo", nearest[0].Data)
+		assert.Equal(t, "bellas-artes", nearest[1].ID)
+		assert.InDelta(t, 900, nearest[1].Distance, 100)
+	}
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -49,50 +51,171 @@ func (app *App) Shutdown() {
 
 // GET /:geohash
 func (app *App) getDataHandler(c echo.Context) error {
-	data := app.DB.Get(c.Param("geohash"))
-	if len(data) == 0 {
+	data, err := app.DB.Get(c.Request().Context(), c.Param("geohash"))
+	if errors.Is(err, ErrNotFound) {
 		return echo.NewHTTPError(http.StatusNotFound, "Geohash not found")
 	}
+	if err != nil {
+		return storageError(err)
+	}
 	return c.JSON(http.StatusOK, string(data))
 }
 
 // POST /:geohash
 func (app *App) postDataHandler(c echo.Context) error {
-	data, err := io.ReadAll(c.Request().Body)
+	data, err := readBody(c)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
-	if len(data) == 0 {
-		return echo.NewHTTPError(http.StatusBadRequest, "Body must have non-zero length")
+	if err := app.DB.Set(c.Request().Context(), c.Param("geohash"), data); err != nil {
+		return storageError(err)
 	}
-	app.DB.Set(c.Param("geohash"), string(data))
 	return c.JSON(http.StatusCreated, c.Param("geohash"))
 }
 
-// GET /:geohash/region
+// PUT /:geohash
+func (app *App) putDataHandler(c echo.Context) error {
+	data, err := readBody(c)
+	if err != nil {
+		return err
+	}
+	created, err := app.DB.Put(c.Request().Context(), c.Param("geohash"), data)
+	if err != nil {
+		return storageError(err)
+	}
+	if created {
+		return c.JSON(http.StatusCreated, c.Param("geohash"))
+	}
+	return c.JSON(http.StatusOK, c.Param("geohash"))
+}
+
+// DELETE /:geohash
+func (app *App) deleteDataHandler(c echo.Context) error {
+	err := app.DB.Delete(c.Request().Context(), c.Param("geohash"))
+	if errors.Is(err, ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "Geohash not found")
+	}
+	if err != nil {
+		return storageError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GET /:geohash/region?limit=&after=
 func (app *App) getRegionDataHandler(c echo.Context) error {
-	data := app.DB.GetAllByPrefix(c.Param("geohash"))
+	prefix := c.Param("geohash")
+	ranges := []keyRange{{start: prefix, end: geohash.PrefixEnd(prefix)}}
+	if acceptsNDJSON(c) {
+		return app.streamNDJSON(c, ranges, "No geohashes found within region", func(key string, value []byte) []StreamRecord {
+			return []StreamRecord{{Geohash: key, Data: string(value)}}
+		})
+	}
+	page, err := queryPage(c)
+	if err != nil {
+		return err
+	}
+	data := make(map[string]string)
+	next, err := app.scanPage(c.Request().Context(), ranges, page, func(key string, value []byte) {
+		data[key] = string(value)
+	})
+	if err != nil {
+		return storageError(err)
+	}
 	if len(data) == 0 {
 		return echo.NewHTTPError(http.StatusNotFound, "No geohashes found within region")
 	}
+	setNextCursor(c, next)
 	return c.JSON(http.StatusOK, data)
 }
 
-// GET /:geohash/neighbours
+// DeleteRegionResponse is the result of deleting all the geohashes within a region
+type DeleteRegionResponse struct {
+	Deleted int  `json:"deleted"`
+	DryRun  bool `json:"dryRun"`
+}
+
+// DELETE /:geohash/region?dryRun=true
+func (app *App) deleteRegionDataHandler(c echo.Context) error {
+	dryRun := c.QueryParam("dryRun") == "true"
+	deleted, err := app.DB.DeletePrefix(c.Request().Context(), c.Param("geohash"), dryRun)
+	if err != nil {
+		return storageError(err)
+	}
+	return c.JSON(http.StatusOK, DeleteRegionResponse{Deleted: deleted, DryRun: dryRun})
+}
+
+// GET /:geohash/neighbours?limit=&after=
 func (app *App) getNeighboursDataHandler(c echo.Context) error {
+	ranges, directions := neighbourRanges(c.Param("geohash"))
+	if acceptsNDJSON(c) {
+		return app.streamNDJSON(c, ranges, "No geohashes found within neighbours", func(key string, value []byte) []StreamRecord {
+			var records []StreamRecord
+			for _, k := range directions[key[:len(c.Param("geohash"))]] {
+				records = append(records, StreamRecord{Direction: k, Geohash: key, Data: string(value)})
+			}
+			return records
+		})
+	}
+	page, err := queryPage(c)
+	if err != nil {
+		return err
+	}
 	data := make(map[string]map[string]string)
-	for k, v := range geohash.Neighbours(c.Param("geohash")) {
-		val := app.DB.GetAllByPrefix(v)
-		if len(val) > 0 {
-			data[k] = val
+	next, err := app.scanPage(c.Request().Context(), ranges, page, func(key string, value []byte) {
+		for _, k := range directions[key[:len(c.Param("geohash"))]] {
+			if data[k] == nil {
+				data[k] = make(map[string]string)
+			}
+			data[k][key] = string(value)
 		}
+	})
+	if err != nil {
+		return storageError(err)
 	}
 	if len(data) == 0 {
 		return echo.NewHTTPError(http.StatusNotFound, "No geohashes found within neighbours")
 	}
+	setNextCursor(c, next)
 	return c.JSON(http.StatusOK, data)
 }
 
+// neighbourRanges returns the sorted key ranges of the neighbours of a geohash, and the
+// directions of each neighbour. Cells at the poles can be the neighbour in more than one.
+func neighbourRanges(hash string) ([]keyRange, map[string][]string) {
+	directions := make(map[string][]string)
+	var ranges []keyRange
+	for k, v := range geohash.Neighbours(hash) {
+		if _, ok := directions[v]; !ok {
+			ranges = append(ranges, keyRange{start: v, end: geohash.PrefixEnd(v)})
+		}
+		directions[v] = append(directions[v], k)
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].start < ranges[j].start
+	})
+	for _, v := range directions {
+		sort.Strings(v)
+	}
+	return ranges, directions
+}
+
+// readBody reads the whole request body, which must not be empty
+func readBody(c echo.Context) ([]byte, error) {
+	data, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if len(data) == 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Body must have non-zero length")
+	}
+	return data, nil
+}
+
+// storageError wraps a database error into an HTTP error, hiding the details from the client
+func storageError(err error) *echo.HTTPError {
+	return echo.NewHTTPError(http.StatusInternalServerError, "Storage error").SetInternal(err)
+}
+
 // ValidateGeohash is a MiddlewareFunc that checks that the given geohash URL parameter is valid
 func ValidateGeohash(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -109,16 +232,27 @@ func (app *App) Configure() {
 	app.echo.Use(middleware.Logger())
 	app.echo.Use(middleware.Recover())
 	app.echo.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{http.MethodGet, http.MethodPost},
+		AllowOrigins:  []string{"*"},
+		AllowMethods:  []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
+		ExposeHeaders: []string{nextCursorHeader},
 	}))
-	app.echo.Use(ValidateGeohash)
 
 	//Routes
-	app.echo.GET("/:geohash", app.getDataHandler)
-	app.echo.POST("/:geohash", app.postDataHandler)
-	app.echo.GET("/:geohash/region", app.getRegionDataHandler)
-	app.echo.GET("/:geohash/neighbours", app.getNeighboursDataHandler)
+	app.echo.GET("/points", app.getPointHandler)
+	app.echo.POST("/points", app.postPointHandler)
+	app.echo.POST("/objects", app.postObjectHandler)
+	app.echo.GET("/objects/:id", app.getObjectHandler)
+	app.echo.GET("/search/bbox", app.getBBoxSearchHandler)
+	app.echo.GET("/search/radius", app.getRadiusSearchHandler)
+	app.echo.POST("/search/polygon", app.postPolygonSearchHandler)
+	app.echo.GET("/search/nearest", app.getNearestSearchHandler)
+	app.echo.GET("/:geohash", app.getDataHandler, ValidateGeohash)
+	app.echo.POST("/:geohash", app.postDataHandler, ValidateGeohash)
+	app.echo.PUT("/:geohash", app.putDataHandler, ValidateGeohash)
+	app.echo.DELETE("/:geohash", app.deleteDataHandler, ValidateGeohash)
+	app.echo.GET("/:geohash/region", app.getRegionDataHandler, ValidateGeohash)
+	app.echo.DELETE("/:geohash/region", app.deleteRegionDataHandler, ValidateGeohash)
+	app.echo.GET("/:geohash/neighbours", app.getNeighboursDataHandler, ValidateGeohash)
 }
 
 // Start the server on a separate goroutine and block until quit signal received
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -16,7 +19,9 @@ import (
 )
 
 type MockDB struct {
-	db map[string]string
+	db      map[string]string
+	objects map[string]Object // by id
+	err     error             // returned by every operation when set
 }
 
 func (mock *MockDB) Open() error {
@@ -27,23 +32,141 @@ func (mock *MockDB) Close() error {
 	return nil
 }
 
-func (mock *MockDB) Set(key, value string) error {
-	mock.db[key] = value
+func (mock *MockDB) Set(ctx context.Context, key string, value []byte) error {
+	if mock.err != nil {
+		return mock.err
+	}
+	mock.db[key] = string(value)
 	return nil
 }
 
-func (mock *MockDB) Get(key string) string {
-	return mock.db[key]
+func (mock *MockDB) Put(ctx context.Context, key string, value []byte) (bool, error) {
+	if mock.err != nil {
+		return false, mock.err
+	}
+	_, exists := mock.db[key]
+	mock.db[key] = string(value)
+	return !exists, nil
+}
+
+func (mock *MockDB) Get(ctx context.Context, key string) ([]byte, error) {
+	if mock.err != nil {
+		return nil, mock.err
+	}
+	v, ok := mock.db[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return []byte(v), nil
 }
 
-func (mock *MockDB) GetAllByPrefix(prefix string) map[string]string {
-	results := make(map[string]string)
+func (mock *MockDB) GetAllByPrefix(ctx context.Context, prefix string) (map[string][]byte, error) {
+	if mock.err != nil {
+		return nil, mock.err
+	}
+	results := make(map[string][]byte)
 	for k, v := range mock.db {
 		if strings.HasPrefix(k, prefix) {
-			results[k] = v
+			results[k] = []byte(v)
+		}
+	}
+	return results, nil
+}
+
+func (mock *MockDB) Delete(ctx context.Context, key string) error {
+	if mock.err != nil {
+		return mock.err
+	}
+	if _, ok := mock.db[key]; !ok {
+		return ErrNotFound
+	}
+	delete(mock.db, key)
+	return nil
+}
+
+func (mock *MockDB) DeletePrefix(ctx context.Context, prefix string, dryRun bool) (int, error) {
+	if mock.err != nil {
+		return 0, mock.err
+	}
+	count := 0
+	for k := range mock.db {
+		if strings.HasPrefix(k, prefix) {
+			count++
+			if !dryRun {
+				delete(mock.db, k)
+			}
+		}
+	}
+	return count, nil
+}
+
+func (mock *MockDB) Scan(ctx context.Context, start, end string, fn func(key string, value []byte) error) error {
+	if mock.err != nil {
+		return mock.err
+	}
+	keys := make([]string, 0, len(mock.db))
+	for k := range mock.db {
+		if k >= start && (end == "" || k < end) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(k, []byte(mock.db[k])); err != nil {
+			return err
 		}
 	}
-	return results
+	return nil
+}
+
+func (mock *MockDB) ScanObjects(ctx context.Context, start, end string, fn func(obj Object) error) error {
+	if mock.err != nil {
+		return mock.err
+	}
+	var objects []Object
+	for _, obj := range mock.objects {
+		if k := obj.Key(); k >= start && (end == "" || k < end) {
+			objects = append(objects, obj)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Key() < objects[j].Key()
+	})
+	for _, obj := range objects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mock *MockDB) SetObject(ctx context.Context, obj Object) (bool, error) {
+	if mock.err != nil {
+		return false, mock.err
+	}
+	if mock.objects == nil {
+		mock.objects = make(map[string]Object)
+	}
+	_, exists := mock.objects[obj.ID]
+	mock.objects[obj.ID] = obj
+	return !exists, nil
+}
+
+func (mock *MockDB) GetObject(ctx context.Context, id string) (Object, error) {
+	if mock.err != nil {
+		return Object{}, mock.err
+	}
+	obj, ok := mock.objects[id]
+	if !ok {
+		return Object{}, ErrNotFound
+	}
+	return obj, nil
 }
 
 // DRYing code, creates a Request and a Response Recorder and sets the geohas to Path context
@@ -66,7 +189,7 @@ func TestPostData(t *testing.T) {
 		"r3gx2ux9dg0p": "Sydney - Opera House",
 		"9g3w81t7mqpx": "Mexico - CDMX Zócalo",
 	}
-	app := NewApp(&MockDB{make(map[string]string)}) // empty database
+	app := NewApp(&MockDB{db: make(map[string]string)}) // empty database
 	defer app.Shutdown()
 	for k, v := range test {
 		// Posting Data
@@ -80,7 +203,7 @@ func TestPostData(t *testing.T) {
 }
 
 func TestPostDataEmpty(t *testing.T) {
-	app := NewApp(&MockDB{make(map[string]string)}) // empty database
+	app := NewApp(&MockDB{db: make(map[string]string)}) // empty database
 	defer app.Shutdown()
 	_, ctx := CreateContextRecord(http.MethodPost, "/:geohash", "", "qwerty")
 	if err := app.postDataHandler(ctx); assert.Error(t, err) {
@@ -92,6 +215,77 @@ func TestPostDataEmpty(t *testing.T) {
 	}
 }
 
+func TestPutData(t *testing.T) {
+	app := NewApp(&MockDB{db: make(map[string]string)}) // empty database
+	defer app.Shutdown()
+	// Create
+	rec, ctx := CreateContextRecord(http.MethodPut, "/:geohash", "Mexico - CDMX Zócalo", "9g3w81t7mqpx")
+	if assert.NoError(t, app.putDataHandler(ctx)) {
+		assert.Equal(t, http.StatusCreated, rec.Code, "Status should be Created - 201")
+		assert.Equal(t, "\"9g3w81t7mqpx\"\n", rec.Body.String(), "Return value should match key")
+	}
+	// Replace
+	rec, ctx = CreateContextRecord(http.MethodPut, "/:geohash", "Mexico - Zócalo", "9g3w81t7mqpx")
+	if assert.NoError(t, app.putDataHandler(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code, "Status should be OK - 200")
+		assert.Equal(t, "Mexico - Zócalo", app.DB.(*MockDB).db["9g3w81t7mqpx"])
+	}
+	// Empty
+	_, ctx = CreateContextRecord(http.MethodPut, "/:geohash", "", "9g3w81t7mqpx")
+	if err := app.putDataHandler(ctx); assert.Error(t, err) {
+		he, ok := err.(*echo.HTTPError)
+		if assert.True(t, ok) {
+			assert.Equal(t, http.StatusBadRequest, he.Code, "Status should be Bad Request - 400")
+		}
+	}
+}
+
+func TestDeleteData(t *testing.T) {
+	app := NewApp(&MockDB{db: map[string]string{"9g3w81t7mqpx": "Mexico - CDMX Zócalo"}})
+	defer app.Shutdown()
+	rec, ctx := CreateContextRecord(http.MethodDelete, "/:geohash", "", "9g3w81t7mqpx")
+	if assert.NoError(t, app.deleteDataHandler(ctx)) {
+		assert.Equal(t, http.StatusNoContent, rec.Code, "Status should be No Content - 204")
+		assert.Empty(t, app.DB.(*MockDB).db)
+	}
+	// Already deleted
+	_, ctx = CreateContextRecord(http.MethodDelete, "/:geohash", "", "9g3w81t7mqpx")
+	if err := app.deleteDataHandler(ctx); assert.Error(t, err) {
+		he, ok := err.(*echo.HTTPError)
+		if assert.True(t, ok) {
+			assert.Equal(t, http.StatusNotFound, he.Code, "Status should be Not Found - 404")
+			assert.Equal(t, "Geohash not found", he.Message)
+		}
+	}
+}
+
+func TestDeleteRegionData(t *testing.T) {
+	region := map[string]string{
+		"9":     "Precision 1",
+		"9e":    "Precision 2a",
+		"9ew":   "Precision 3a",
+		"9b":    "Precision 2b",
+		"9bnrt": "Precision 5b",
+		"d":     "Another region",
+	}
+	app := NewApp(&MockDB{db: region})
+	defer app.Shutdown()
+	// Dry run only counts
+	rec, ctx := CreateContextRecord(http.MethodDelete, "/:geohash/region", "", "9e")
+	ctx.Request().URL.RawQuery = "dryRun=true"
+	if assert.NoError(t, app.deleteRegionDataHandler(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code, "Status should be 200")
+		assert.Equal(t, `{"deleted":2,"dryRun":true}`+"\n", rec.Body.String())
+		assert.Len(t, region, 6)
+	}
+	rec, ctx = CreateContextRecord(http.MethodDelete, "/:geohash/region", "", "9")
+	if assert.NoError(t, app.deleteRegionDataHandler(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code, "Status should be 200")
+		assert.Equal(t, `{"deleted":5,"dryRun":false}`+"\n", rec.Body.String())
+		assert.Equal(t, map[string]string{"d": "Another region"}, region)
+	}
+}
+
 func TestGetDataAndValidateMiddleware(t *testing.T) {
 	test := map[string]string{
 		"3e4mbr3q2w39": "Chile - Easter Island, Anakena Beach",
@@ -100,7 +294,7 @@ func TestGetDataAndValidateMiddleware(t *testing.T) {
 		"r3gx2ux9dg0p": "Sydney - Opera House",
 		"9g3w81t7mqpx": "Mexico - CDMX Zócalo",
 	}
-	app := NewApp(&MockDB{test})
+	app := NewApp(&MockDB{db: test})
 	defer app.Shutdown()
 	for k, v := range test {
 		// Getting Data
@@ -114,7 +308,7 @@ func TestGetDataAndValidateMiddleware(t *testing.T) {
 }
 
 func TestGetDataNotFound(t *testing.T) {
-	app := NewApp(&MockDB{make(map[string]string)}) //Empty database
+	app := NewApp(&MockDB{db: make(map[string]string)}) //Empty database
 	defer app.Shutdown()
 	notFound := []string{"12345678", "90qwerty", "upsdfghj", "kzxcvbnm"}
 	for _, v := range notFound {
@@ -143,7 +337,7 @@ func TestRegionData(t *testing.T) {
 		"9bnr":  "Precision 4b",
 		"9bnrt": "Precision 5b",
 	}
-	app := NewApp(&MockDB{region})
+	app := NewApp(&MockDB{db: region})
 	defer app.Shutdown()
 	// Getting Valid Region Data
 	rec, ctx := CreateContextRecord(http.MethodGet, "/:geohash/region", "", "9")
@@ -178,7 +372,7 @@ func TestNeighboursData(t *testing.T) {
 		"3": "South",
 		"6": "South East",
 	}
-	app := NewApp(&MockDB{neighbours})
+	app := NewApp(&MockDB{db: neighbours})
 	defer app.Shutdown()
 	for k := range neighbours {
 		rec, ctx := CreateContextRecord(http.MethodGet, "/:geohash/neighbours", "", k)
@@ -201,7 +395,7 @@ func TestNeighboursData(t *testing.T) {
 }
 
 func TestInvalidGeohash(t *testing.T) {
-	app := NewApp(&MockDB{make(map[string]string)}) // empty database
+	app := NewApp(&MockDB{db: make(map[string]string)}) // empty database
 	defer app.Shutdown()
 	// Setup
 	invalid := []string{
@@ -225,14 +419,47 @@ func TestInvalidGeohash(t *testing.T) {
 	}
 }
 
+func TestStorageErrors(t *testing.T) {
+	app := NewApp(&MockDB{db: make(map[string]string), err: errors.New("disk on fire")})
+	defer app.Shutdown()
+	handlers := map[string]echo.HandlerFunc{
+		"GET /:geohash":            app.getDataHandler,
+		"DELETE /:geohash":         app.deleteDataHandler,
+		"GET /:geohash/region":     app.getRegionDataHandler,
+		"DELETE /:geohash/region":  app.deleteRegionDataHandler,
+		"GET /:geohash/neighbours": app.getNeighboursDataHandler,
+	}
+	for route, handler := range handlers {
+		method, path, _ := strings.Cut(route, " ")
+		_, ctx := CreateContextRecord(method, path, "", "9g3w")
+		if err := handler(ctx); assert.Error(t, err, route) {
+			he, ok := err.(*echo.HTTPError)
+			if assert.True(t, ok) {
+				assert.Equal(t, http.StatusInternalServerError, he.Code, "Status should be Internal Server Error - 500")
+				assert.Equal(t, "Storage error", he.Message)
+				assert.EqualError(t, he.Internal, "disk on fire")
+			}
+		}
+	}
+	for _, handler := range []echo.HandlerFunc{app.postDataHandler, app.putDataHandler} {
+		_, ctx := CreateContextRecord(http.MethodPost, "/:geohash", "data", "9g3w")
+		if err := handler(ctx); assert.Error(t, err) {
+			he, ok := err.(*echo.HTTPError)
+			if assert.True(t, ok) {
+				assert.Equal(t, http.StatusInternalServerError, he.Code, "Status should be Internal Server Error - 500")
+			}
+		}
+	}
+}
+
 func TestAppConfiguration(t *testing.T) {
 	os.Setenv("PORT", "")
-	app := NewApp(&MockDB{make(map[string]string)})
+	app := NewApp(&MockDB{db: make(map[string]string)})
 	// Test default port
 	assert.Equal(t, "3000", app.port)
 	// Setting new port
 	os.Setenv("PORT", "3001")
-	app = NewApp(&MockDB{make(map[string]string)})
+	app = NewApp(&MockDB{db: make(map[string]string)})
 	assert.Equal(t, "3001", app.port)
 	app.Configure()
 	// 4 routes defined
@@ -240,7 +467,7 @@ func TestAppConfiguration(t *testing.T) {
 }
 
 func TestAppStartAndGracefulShutdown(t *testing.T) {
-	app := NewApp(&MockDB{make(map[string]string)})
+	app := NewApp(&MockDB{db: make(map[string]string)})
 	stop := make(chan os.Signal, 1)
 	go func() {
 		log.Println("Sleeping for 4 seconds to test server shutdown")
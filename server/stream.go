@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// mimeNDJSON is the media type of newline delimited JSON, one record per line
+const mimeNDJSON = "application/x-ndjson"
+
+// flushEvery is how many keys are scanned in a batch, whose records are then written and
+// flushed to the client
+const flushEvery = 100
+
+// StreamRecord is a line of a streamed response, direction is only set for neighbours
+type StreamRecord struct {
+	Direction string `json:"direction,omitempty"`
+	Geohash   string `json:"geohash"`
+	Data      string `json:"data"`
+}
+
+// acceptsNDJSON checks if the client asked for a streamed response
+func acceptsNDJSON(c echo.Context) bool {
+	return strings.Contains(c.Request().Header.Get(echo.HeaderAccept), mimeNDJSON)
+}
+
+// streamNDJSON writes the records of every key in the ranges without paginating. Keys are
+// scanned in batches of flushEvery, and each batch is written and flushed once its read
+// transaction is over, so a slow client never holds one open. The response starts with the
+// first record, so an empty scan is still reported with notFound. A client going away stops
+// the stream quietly, either by canceling the request context or failing the writes.
+func (app *App) streamNDJSON(c echo.Context, ranges []keyRange, notFound string, records func(key string, value []byte) []StreamRecord) error {
+	res := c.Response()
+	enc := json.NewEncoder(res)
+	after := ""
+	for {
+		var batch []StreamRecord
+		last, more, err := app.scanBatch(c.Request().Context(), ranges, after, flushEvery, func(key string, value []byte) {
+			batch = append(batch, records(key, value)...)
+		})
+		if errors.Is(err, context.Canceled) {
+			return nil // the client went away, nobody is left to tell
+		}
+		if err != nil {
+			// Once the response started the error can only be logged
+			return storageError(err)
+		}
+		for _, record := range batch {
+			if !res.Committed {
+				res.Header().Set(echo.HeaderContentType, mimeNDJSON)
+				res.WriteHeader(http.StatusOK)
+			}
+			if err := enc.Encode(record); err != nil {
+				return nil // the connection is gone, it is not a storage error
+			}
+		}
+		if res.Committed {
+			res.Flush()
+		}
+		if !more {
+			break
+		}
+		after = last
+	}
+	if !res.Committed {
+		return echo.NewHTTPError(http.StatusNotFound, notFound)
+	}
+	return nil
+}
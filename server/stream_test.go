@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stream sends a request asking for NDJSON to a configured app
+func stream(app *App, target string, w http.ResponseWriter, ctx context.Context) {
+	req := httptest.NewRequest(http.MethodGet, target, nil).WithContext(ctx)
+	req.Header.Set("Accept", mimeNDJSON)
+	app.echo.ServeHTTP(w, req)
+}
+
+// cancelOnFlush is a response recorder that cancels the request, as a client going away, on the first flush
+type cancelOnFlush struct {
+	*httptest.ResponseRecorder
+	cancel context.CancelFunc
+}
+
+func (w cancelOnFlush) Flush() {
+	w.ResponseRecorder.Flush()
+	w.cancel()
+}
+
+// failingWriter is a response recorder whose writes fail, as a connection reset by the client
+type failingWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset by peer")
+}
+
+// scanTracker is a database telling if a scan is in progress
+type scanTracker struct {
+	Database
+	scanning bool
+}
+
+func (db *scanTracker) Scan(ctx context.Context, start, end string, fn func(key string, value []byte) error) error {
+	db.scanning = true
+	defer func() { db.scanning = false }()
+	return db.Database.Scan(ctx, start, end, fn)
+}
+
+// writeOutsideScan is a response recorder failing the test when written during a scan
+type writeOutsideScan struct {
+	*httptest.ResponseRecorder
+	t  *testing.T
+	db *scanTracker
+}
+
+func (w writeOutsideScan) Write(b []byte) (int, error) {
+	assert.False(w.t, w.db.scanning, "Written while scanning")
+	return w.ResponseRecorder.Write(b)
+}
+
+func TestStreamRegion(t *testing.T) {
+	app := NewApp(&MockDB{db: map[string]string{"9": "1", "9b": "2", "9bn": "3", "b": "Outside"}})
+	defer app.Shutdown()
+	app.Configure()
+	rec := httptest.NewRecorder()
+	stream(app, "/9/region?limit=1", rec, context.Background())
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, mimeNDJSON, rec.Header().Get("Content-Type"))
+	assert.Empty(t, rec.Header().Get(nextCursorHeader), "Streams are not paginated")
+	assert.Equal(t, `{"geohash":"9","data":"1"}
+{"geohash":"9b","data":"2"}
+{"geohash":"9bn","data":"3"}
+`, rec.Body.String())
+	assert.True(t, rec.Flushed)
+	// Not found
+	rec = httptest.NewRecorder()
+	stream(app, "/8/region", rec, context.Background())
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), "No geohashes found within region")
+	// Storage errors before the response starts
+	app.DB.(*MockDB).err = errors.New("disk on fire")
+	rec = httptest.NewRecorder()
+	stream(app, "/9/region", rec, context.Background())
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestStreamNeighbours(t *testing.T) {
+	app := NewApp(&MockDB{db: map[string]string{"9": "Center", "c": "North", "c1": "North 2", "6zz": "South East"}})
+	defer app.Shutdown()
+	app.Configure()
+	rec := httptest.NewRecorder()
+	stream(app, "/9/neighbours", rec, context.Background())
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"direction":"se","geohash":"6zz","data":"South East"}
+{"direction":"n","geohash":"c","data":"North"}
+{"direction":"n","geohash":"c1","data":"North 2"}
+`, rec.Body.String())
+	rec = httptest.NewRecorder()
+	stream(app, "/u/neighbours", rec, context.Background())
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestStreamClientDisconnect(t *testing.T) {
+	db := &MockDB{db: make(map[string]string)}
+	for i := 0; i < 3*flushEvery; i++ {
+		db.db[fmt.Sprintf("9%04d", i)] = "x"
+	}
+	app := NewApp(db)
+	defer app.Shutdown()
+	app.Configure()
+	ctx, cancel := context.WithCancel(context.Background())
+	rec := cancelOnFlush{httptest.NewRecorder(), cancel}
+	stream(app, "/9/region", rec, ctx)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, flushEvery, strings.Count(rec.Body.String(), "\n"), "Should stop after the first flush")
+	// Gone before anything was written
+	rec = cancelOnFlush{httptest.NewRecorder(), cancel}
+	stream(app, "/9/region", rec, ctx)
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestStreamBatches(t *testing.T) {
+	mock := &MockDB{db: make(map[string]string)}
+	for i := 0; i < 2*flushEvery+1; i++ {
+		mock.db[fmt.Sprintf("9%04d", i)] = "x"
+	}
+	db := &scanTracker{Database: mock}
+	app := NewApp(db)
+	defer app.Shutdown()
+	app.Configure()
+	rec := writeOutsideScan{httptest.NewRecorder(), t, db}
+	stream(app, "/9/region", rec, context.Background())
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 2*flushEvery+1, strings.Count(rec.Body.String(), "\n"))
+	// Failed writes stop the stream, they are not storage errors
+	req := httptest.NewRequest(http.MethodGet, "/9/region", nil)
+	req.Header.Set("Accept", mimeNDJSON)
+	c := app.echo.NewContext(req, failingWriter{httptest.NewRecorder()})
+	c.SetParamNames("geohash")
+	c.SetParamValues("9")
+	assert.NoError(t, app.getRegionDataHandler(c))
+}
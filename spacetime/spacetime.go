@@ -0,0 +1,238 @@
+// Package spacetime builds sortable keys combining a geohash and a time bucket, to store
+// and query data by both where and when it happened. Keys either sort by space first
+// and then time, or interleave the characters of both so neither dominates.
+package spacetime
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/phrozen/geohash"
+)
+
+// timeChars is the number of characters encoding a time bucket (40 bits)
+const timeChars = 8
+
+// timeOffset shifts bucket numbers so the ones before 1970 are positive too
+const timeOffset = int64(1) << 39
+
+// maxBucket is the last bucket number that fits in timeChars
+const maxBucket = int64(1)<<(5*timeChars) - 1
+
+var (
+	// ErrInvalidGeohash is returned for geohashes with invalid characters or too short for the Keyer
+	ErrInvalidGeohash = errors.New("spacetime: invalid geohash")
+	// ErrInvalidKey is returned when decoding a key not built by the same Keyer
+	ErrInvalidKey = errors.New("spacetime: invalid key")
+	// ErrInvalidGranularity is returned when creating a Keyer with a granularity that is not positive
+	ErrInvalidGranularity = errors.New("spacetime: invalid granularity")
+	// ErrInvalidPrecision is returned when creating a Keyer with a negative precision
+	ErrInvalidPrecision = errors.New("spacetime: invalid precision")
+	// ErrTimeOutOfRange is returned for times whose bucket does not fit in a key, too far
+	// from the epoch for the Keyer's granularity
+	ErrTimeOutOfRange = errors.New("spacetime: time out of range")
+)
+
+// Layout is the arrangement of the geohash and time characters in a key
+type Layout int
+
+const (
+	// SpaceFirst keys are the geohash followed by the time bucket,
+	// they sort by location and then by time.
+	SpaceFirst Layout = iota
+	// Interleaved keys alternate geohash and time bucket characters,
+	// each additional pair narrowing both the area and the interval.
+	Interleaved
+)
+
+// Keyer builds keys from geohashes of a fixed precision and times truncated to a granularity
+type Keyer struct {
+	precision   int
+	granularity time.Duration
+	layout      Layout
+}
+
+// Range is an interval of keys, Start included and End excluded. An empty End has no upper bound.
+type Range struct {
+	Start, End string
+}
+
+// New creates a Keyer for geohashes with the given precision and time buckets of the given
+// granularity (counted from the Unix epoch), arranged in the given layout. Keys hold 2^40
+// buckets around the epoch, about 17 years either way for millisecond buckets.
+func New(precision int, granularity time.Duration, layout Layout) (Keyer, error) {
+	if precision < 0 {
+		return Keyer{}, ErrInvalidPrecision
+	}
+	if granularity <= 0 {
+		return Keyer{}, ErrInvalidGranularity
+	}
+	return Keyer{precision: precision, granularity: granularity, layout: layout}, nil
+}
+
+// Key builds the key for a geohash and a time. Longer geohashes are truncated to the Keyer's precision.
+func (k Keyer) Key(hash string, t time.Time) (string, error) {
+	if len(hash) < k.precision || !geohash.Valid(hash) {
+		return "", ErrInvalidGeohash
+	}
+	bucket := k.bucket(t)
+	if bucket < 0 || bucket > maxBucket {
+		return "", ErrTimeOutOfRange
+	}
+	return k.join(hash[:k.precision], bucket), nil
+}
+
+// Decode splits a key back into its geohash and the start of its time bucket,
+// which spans until the start plus the Keyer's granularity.
+func (k Keyer) Decode(key string) (string, time.Time, error) {
+	if len(key) != k.precision+timeChars {
+		return "", time.Time{}, ErrInvalidKey
+	}
+	var hash, bucket []byte
+	for i, slot := range k.slots() {
+		if strings.IndexByte(geohash.Alphabet, key[i]) < 0 {
+			return "", time.Time{}, ErrInvalidKey
+		}
+		if slot {
+			hash = append(hash, key[i])
+		} else {
+			bucket = append(bucket, key[i])
+		}
+	}
+	n, _ := bucketSpan(string(bucket))
+	return string(hash), k.start(n), nil
+}
+
+// Ranges calculates the key ranges covering the region during the interval between from
+// and to (both included), sorted and merged. Keys in the ranges may lie slightly outside
+// the region or interval at the edges, to the Keyer's precision and granularity. The
+// interval is clipped to the times keys can hold.
+func (k Keyer) Ranges(r geohash.Region, from, to time.Time) []Range {
+	q := query{region: r, from: max(k.bucket(from), 0), to: min(k.bucket(to), maxBucket), precision: k.precision, slots: k.slots()}
+	if q.from > q.to {
+		return nil
+	}
+	var ranges []Range
+	q.descend("", "", 0, func(prefix string) {
+		end := geohash.PrefixEnd(prefix)
+		if n := len(ranges); n > 0 && ranges[n-1].End == prefix {
+			ranges[n-1].End = end
+			return
+		}
+		ranges = append(ranges, Range{Start: prefix, End: end})
+	})
+	return ranges
+}
+
+// bucket numbers the time bucket containing t, offset to be positive
+func (k Keyer) bucket(t time.Time) int64 {
+	ns := t.UnixNano()
+	n := ns / int64(k.granularity)
+	if ns%int64(k.granularity) < 0 {
+		n-- // floor for times before the epoch
+	}
+	return n + timeOffset
+}
+
+// start returns the time at the start of a bucket
+func (k Keyer) start(bucket int64) time.Time {
+	return time.Unix(0, (bucket-timeOffset)*int64(k.granularity)).UTC()
+}
+
+// slots tells for each character of a key if it belongs to the geohash (true) or the time bucket
+func (k Keyer) slots() []bool {
+	slots := make([]bool, 0, k.precision+timeChars)
+	if k.layout == SpaceFirst {
+		for i := 0; i < k.precision; i++ {
+			slots = append(slots, true)
+		}
+		for i := 0; i < timeChars; i++ {
+			slots = append(slots, false)
+		}
+		return slots
+	}
+	for i := 0; i < k.precision || i < timeChars; i++ {
+		if i < k.precision {
+			slots = append(slots, true)
+		}
+		if i < timeChars {
+			slots = append(slots, false)
+		}
+	}
+	return slots
+}
+
+// join arranges the geohash and time bucket characters according to the layout
+func (k Keyer) join(hash string, bucket int64) string {
+	chars := make([]byte, timeChars)
+	for i := range chars {
+		chars[i] = geohash.Alphabet[bucket>>(5*(timeChars-1-i))&31]
+	}
+	return interleave(hash, string(chars), k.slots())
+}
+
+// query is a region and interval of buckets to cover with key prefixes
+type query struct {
+	region    geohash.Region
+	from, to  int64
+	precision int
+	slots     []bool
+}
+
+// descend visits the prefixes intersecting the query, emitting the ones completely
+// inside it (or at full length) in key order.
+func (q query) descend(hash, bucket string, depth int, emit func(string)) {
+	for i := 0; i < len(geohash.Alphabet); i++ {
+		h, b := hash, bucket
+		if q.slots[depth] {
+			h += geohash.Alphabet[i : i+1]
+		} else {
+			b += geohash.Alphabet[i : i+1]
+		}
+		cell := geohash.NewRegion(geohash.NewLocation(-90, -180), geohash.NewLocation(90, 180))
+		if h != "" {
+			cell = geohash.Decode(h)
+		}
+		lo, hi := bucketSpan(b)
+		if !cell.Intersects(q.region) || hi < q.from || lo > q.to {
+			continue
+		}
+		key := interleave(h, b, q.slots)
+		// Geohashes at full precision can't be narrowed down, so they count as inside the region
+		inRegion := len(h) == q.precision || q.region.Contains(cell.Min()) && q.region.Contains(cell.Max())
+		inside := inRegion && lo >= q.from && hi <= q.to
+		if inside || depth+1 == len(q.slots) {
+			emit(key)
+		} else {
+			q.descend(h, b, depth+1, emit)
+		}
+	}
+}
+
+// bucketSpan returns the first and last bucket numbers starting with the given characters
+func bucketSpan(prefix string) (lo, hi int64) {
+	for _, char := range []byte(prefix) {
+		lo = lo<<5 | int64(strings.IndexByte(geohash.Alphabet, char))
+	}
+	shift := 5 * (timeChars - len(prefix))
+	return lo << shift, (lo+1)<<shift - 1
+}
+
+// interleave arranges partial geohash and time characters following the slots
+func interleave(hash, bucket string, slots []bool) string {
+	var key strings.Builder
+	h, b := 0, 0
+	for _, slot := range slots {
+		if slot && h < len(hash) {
+			key.WriteByte(hash[h])
+			h++
+		} else if !slot && b < len(bucket) {
+			key.WriteByte(bucket[b])
+			b++
+		} else {
+			break
+		}
+	}
+	return key.String()
+}
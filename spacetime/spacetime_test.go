@@ -0,0 +1,146 @@
+package spacetime
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/phrozen/geohash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyDecode(t *testing.T) {
+	when := time.Date(2023, 11, 20, 13, 45, 12, 0, time.UTC)
+	for _, layout := range []Layout{SpaceFirst, Interleaved} {
+		k, err := New(6, time.Hour, layout)
+		assert.NoError(t, err)
+		key, err := k.Key("9g3w81t7mqpx", when)
+		if assert.NoError(t, err) {
+			assert.Len(t, key, 6+timeChars)
+			hash, start, err := k.Decode(key)
+			assert.NoError(t, err)
+			assert.Equal(t, "9g3w81", hash)
+			assert.Equal(t, time.Date(2023, 11, 20, 13, 0, 0, 0, time.UTC), start)
+		}
+		// Before the epoch
+		old := time.Date(1969, 7, 20, 20, 17, 0, 0, time.UTC)
+		key, _ = k.Key("9g3w81", old)
+		_, start, _ := k.Decode(key)
+		assert.Equal(t, time.Date(1969, 7, 20, 20, 0, 0, 0, time.UTC), start)
+		// Errors
+		_, err = k.Key("9g3w", when)
+		assert.ErrorIs(t, err, ErrInvalidGeohash)
+		_, err = k.Key("abcdefgh", when)
+		assert.ErrorIs(t, err, ErrInvalidGeohash)
+		_, _, err = k.Decode("9g3w")
+		assert.ErrorIs(t, err, ErrInvalidKey)
+		_, _, err = k.Decode("9g3w81aaaaaaaa")
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	}
+	// Layouts
+	k, _ := New(3, time.Hour, SpaceFirst)
+	spaceFirst, _ := k.Key("9g3", when)
+	k, _ = New(3, time.Hour, Interleaved)
+	interleaved, _ := k.Key("9g3", when)
+	assert.Equal(t, "9g3", spaceFirst[:3])
+	assert.Equal(t, "9g3", string([]byte{interleaved[0], interleaved[2], interleaved[4]}))
+	assert.Equal(t, spaceFirst[3:], string([]byte{interleaved[1], interleaved[3]})+interleaved[5:])
+}
+
+func TestKeyOrdering(t *testing.T) {
+	k, _ := New(4, time.Minute, SpaceFirst)
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	var keys []string
+	for i := 0; i < 100; i++ {
+		key, _ := k.Key("9g3w", start.Add(time.Duration(i)*7*time.Minute))
+		keys = append(keys, key)
+	}
+	assert.True(t, sort.StringsAreSorted(keys), "Same place keys sort by time")
+	before, _ := k.Key("9g3w", start.Add(time.Hour))
+	after, _ := k.Key("9g3x", start)
+	assert.Less(t, before, after, "Space sorts first")
+}
+
+func TestRanges(t *testing.T) {
+	region := geohash.NewRegion(geohash.NewLocation(19.3, -99.3), geohash.NewLocation(19.6, -99.0))
+	from := time.Date(2023, 11, 20, 8, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 11, 20, 17, 59, 0, 0, time.UTC)
+	for _, layout := range []Layout{SpaceFirst, Interleaved} {
+		k, _ := New(5, time.Hour, layout)
+		ranges := k.Ranges(region, from, to)
+		assert.NotEmpty(t, ranges)
+		inRanges := func(key string) bool {
+			for _, r := range ranges {
+				if key >= r.Start && (r.End == "" || key < r.End) {
+					return true
+				}
+			}
+			return false
+		}
+		// Sorted and merged
+		for i := 1; i < len(ranges); i++ {
+			assert.Less(t, ranges[i-1].End, ranges[i].Start)
+		}
+		// Every key inside the region and interval is in a range, keys far away are not
+		for lat := 19.3; lat <= 19.6; lat += 0.02 {
+			for lon := -99.3; lon <= -99.0; lon += 0.02 {
+				hash := geohash.Encode(lat, lon, 5)
+				for h := 8; h < 18; h++ {
+					key, _ := k.Key(hash, from.Add(time.Duration(h-8)*time.Hour+30*time.Minute))
+					assert.True(t, inRanges(key), key)
+				}
+				key, _ := k.Key(hash, from.Add(-2*time.Hour))
+				assert.False(t, inRanges(key), key)
+				key, _ = k.Key(hash, to.Add(2*time.Hour))
+				assert.False(t, inRanges(key), key)
+			}
+		}
+		for _, hash := range []string{"sr2y7", geohash.Encode(25, -99.1, 5), geohash.Encode(19.4, -98, 5)} {
+			key, _ := k.Key(hash, from.Add(time.Hour))
+			assert.False(t, inRanges(key), key)
+		}
+	}
+	// Empty interval
+	k, _ := New(5, time.Hour, SpaceFirst)
+	assert.Empty(t, k.Ranges(region, to, from))
+	// Clipped to the times keys can hold, millisecond buckets end in 1987
+	k, _ = New(5, time.Millisecond, SpaceFirst)
+	ranges := k.Ranges(region, time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))
+	if assert.NotEmpty(t, ranges) {
+		key, _ := k.Key(geohash.Encode(19.4, -99.1, 5), time.Date(1987, 1, 1, 0, 0, 0, 0, time.UTC))
+		assert.True(t, key >= ranges[0].Start && (ranges[len(ranges)-1].End == "" || key < ranges[len(ranges)-1].End), key)
+	}
+	assert.Empty(t, k.Ranges(region, time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestPrecision(t *testing.T) {
+	_, err := New(-1, time.Hour, SpaceFirst)
+	assert.ErrorIs(t, err, ErrInvalidPrecision)
+	// Precision 0 keys hold only the time bucket
+	k, err := New(0, time.Hour, SpaceFirst)
+	if assert.NoError(t, err) {
+		key, err := k.Key("9g3w", time.Unix(0, 0))
+		assert.NoError(t, err)
+		assert.Len(t, key, timeChars)
+	}
+}
+
+func TestGranularity(t *testing.T) {
+	for _, granularity := range []time.Duration{0, -time.Second} {
+		_, err := New(6, granularity, SpaceFirst)
+		assert.ErrorIs(t, err, ErrInvalidGranularity, granularity)
+	}
+	// Millisecond buckets reach about 17 years from the epoch
+	k, err := New(6, time.Millisecond, SpaceFirst)
+	assert.NoError(t, err)
+	when := time.Date(1980, 1, 1, 12, 0, 0, 123000000, time.UTC)
+	key, err := k.Key("9g3w81", when)
+	if assert.NoError(t, err) {
+		_, start, _ := k.Decode(key)
+		assert.Equal(t, when, start)
+	}
+	_, err = k.Key("9g3w81", time.Date(2026, 10, 18, 12, 0, 0, 123000000, time.UTC))
+	assert.ErrorIs(t, err, ErrTimeOutOfRange)
+	_, err = k.Key("9g3w81", time.Date(1950, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, ErrTimeOutOfRange)
+}